@@ -3,9 +3,12 @@ package main
 import (
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/arnold/bingoals-api/internal/config"
 	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/handlers"
 	"github.com/arnold/bingoals-api/internal/routes"
 	"github.com/arnold/bingoals-api/internal/services"
 	"github.com/gofiber/fiber/v2"
@@ -33,12 +36,47 @@ func main() {
 		log.Fatalf("Failed to run migrations: %v", err)
 	}
 
+	// Seed built-in board templates
+	if err := database.Seed(); err != nil {
+		log.Fatalf("Failed to seed database: %v", err)
+	}
+
 	// Initialize push notifications (no-op if not configured)
 	services.InitPush(cfg.FCMServiceAccount)
 
+	// Initialize Google ID token verification (keys are fetched on first login)
+	services.InitGoogleAuth(cfg.GoogleJWKSURL, cfg.GoogleClientIDs)
+
+	// Initialize mailer (logs instead of sending if SMTP is not configured)
+	services.InitMailer(cfg)
+	handlers.AppURL = cfg.AppURL
+
+	// Purge trashed boards, goals and mini-goals after the retention period
+	handlers.TrashRetention = time.Duration(cfg.TrashRetentionDays) * 24 * time.Hour
+	handlers.StartTrashPurge()
+
+	// Streak freezes are bought with gems and used up by a scheduled job
+	handlers.StreakFreezePrice = cfg.StreakFreezePrice
+	handlers.StreakFreezeMax = cfg.StreakFreezeMax
+
+	// Run scheduled jobs such as goal reminders; jobs are stored in the database
+	handlers.RegisterReminderJobs()
+	handlers.RegisterStreakJobs()
+	handlers.LoginAttemptRetention = time.Duration(cfg.LoginAttemptDays) * 24 * time.Hour
+	handlers.RegisterLoginAttemptJobs()
+	services.StartScheduler(30 * time.Second)
+
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
-		AppName: "Bingoals API",
+		AppName:      "Bingoals API",
+		ErrorHandler: handlers.ErrorHandler,
+
+		// c.IP() reads the client IP from ProxyHeader only for requests that
+		// come through a trusted proxy; everyone else gets the socket address
+		ProxyHeader:             cfg.ProxyHeader,
+		EnableTrustedProxyCheck: true,
+		TrustedProxies:          splitList(cfg.TrustedProxies),
+		EnableIPValidation:      true,
 	})
 
 	// Middleware
@@ -71,3 +109,14 @@ func main() {
 		log.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// splitList splits a comma-separated setting, dropping blanks
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
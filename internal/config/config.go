@@ -1,13 +1,30 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+)
 
 type Config struct {
-	DatabaseURL    string
-	JWTSecret      string
-	Port           string
+	DatabaseURL        string
+	JWTSecret          string
+	Port               string
 	GoogleClientIDs    string
+	GoogleJWKSURL      string
 	FCMServiceAccount  string
+	AppURL             string
+	SMTPHost           string
+	SMTPPort           string
+	SMTPUsername       string
+	SMTPPassword       string
+	MailFrom           string
+	MailLogFile        string
+	TrashRetentionDays int
+	StreakFreezePrice  int
+	StreakFreezeMax    int
+	TrustedProxies     string
+	ProxyHeader        string
+	LoginAttemptDays   int
 }
 
 func Load() *Config {
@@ -16,7 +33,21 @@ func Load() *Config {
 		JWTSecret:          getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
 		Port:               getEnv("PORT", "8080"),
 		GoogleClientIDs:    getEnv("GOOGLE_CLIENT_IDS", ""),
+		GoogleJWKSURL:      getEnv("GOOGLE_JWKS_URL", "https://www.googleapis.com/oauth2/v3/certs"),
 		FCMServiceAccount:  getEnv("FCM_SERVICE_ACCOUNT", ""),
+		AppURL:             getEnv("APP_URL", "http://localhost:8080"),
+		SMTPHost:           getEnv("SMTP_HOST", ""),
+		SMTPPort:           getEnv("SMTP_PORT", "587"),
+		SMTPUsername:       getEnv("SMTP_USERNAME", ""),
+		SMTPPassword:       getEnv("SMTP_PASSWORD", ""),
+		MailFrom:           getEnv("MAIL_FROM", "Bingoals <no-reply@bingoals.app>"),
+		MailLogFile:        getEnv("MAIL_LOG_FILE", ""),
+		TrashRetentionDays: getEnvInt("TRASH_RETENTION_DAYS", 30),
+		StreakFreezePrice:  getEnvInt("STREAK_FREEZE_PRICE", 50),
+		StreakFreezeMax:    getEnvInt("STREAK_FREEZE_MAX", 2),
+		TrustedProxies:     getEnv("TRUSTED_PROXIES", ""),
+		ProxyHeader:        getEnv("PROXY_HEADER", "X-Forwarded-For"),
+		LoginAttemptDays:   getEnvInt("LOGIN_ATTEMPT_RETENTION_DAYS", 90),
 	}
 }
 
@@ -26,3 +57,12 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) int {
+	if value, exists := os.LookupEnv(key); exists {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return fallback
+}
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/arnold/bingoals-api/internal/config"
@@ -35,7 +36,7 @@ func Connect(cfg *config.Config) error {
 }
 
 func Migrate() error {
-	return DB.AutoMigrate(
+	if err := DB.AutoMigrate(
 		&models.User{},
 		&models.Board{},
 		&models.Goal{},
@@ -50,5 +51,123 @@ func Migrate() error {
 		&models.GoalMember{},
 		&models.MiniGoalMember{},
 		&models.GoalMemory{},
-	)
+		&models.Session{},
+		&models.OneTimeToken{},
+		&models.UserIdentity{},
+		&models.RecoveryCode{},
+		&models.LoginAttempt{},
+		&models.BoardTemplate{},
+		&models.BoardTemplateGoal{},
+		&models.BoardTemplateMiniGoal{},
+		&models.ReminderRule{},
+		&models.ScheduledJob{},
+		&models.GoalCheckIn{},
+		&models.GoalStreak{},
+		&models.GemTransaction{},
+		&models.StreakFreezeLog{},
+		&models.UserAchievement{},
+		&models.BoardMilestone{},
+	); err != nil {
+		return err
+	}
+
+	if err := backfillIdentities(); err != nil {
+		return err
+	}
+
+	// Boards used to have only "owner" and "member"; members could edit everything.
+	// Unscoped so members restored with a trashed board don't come back with the old role.
+	if err := DB.Unscoped().Model(&models.BoardMember{}).
+		Where("role = ?", "member").
+		Update("role", models.RoleEditor).Error; err != nil {
+		return err
+	}
+
+	if err := backfillBoardMilestones(); err != nil {
+		return err
+	}
+	return reconcileGems()
+}
+
+// backfillIdentities gives accounts created through Google before identities
+// were recorded a Google identity, read from the old users.auth_provider
+// column. Their subject is filled in on the next Google sign-in.
+func backfillIdentities() error {
+	if !DB.Migrator().HasColumn(&models.User{}, "auth_provider") {
+		return nil
+	}
+
+	var users []models.User
+	DB.Where("auth_provider = ?", models.ProviderGoogle).
+		Where("NOT EXISTS (SELECT 1 FROM user_identities WHERE user_identities.user_id = users.id AND user_identities.provider = ?)", models.ProviderGoogle).
+		Find(&users)
+	for _, u := range users {
+		identity := models.UserIdentity{
+			UserID:   u.ID,
+			Provider: models.ProviderGoogle,
+			Subject:  models.LegacySubjectPrefix + u.ID.String(),
+			Email:    u.Email,
+		}
+		if err := DB.Create(&identity).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillBoardMilestones records a BoardMilestone for every live milestone
+// award on the gem ledger from before milestones were stored
+func backfillBoardMilestones() error {
+	var awards []models.GemTransaction
+	DB.Where("reason = ? AND reversed_at IS NULL AND board_id IS NOT NULL AND milestone IS NOT NULL", models.GemReasonMilestone).
+		Where("EXISTS (SELECT 1 FROM boards WHERE boards.id = gem_transactions.board_id)").
+		Where("NOT EXISTS (SELECT 1 FROM board_milestones WHERE board_milestones.board_id = gem_transactions.board_id" +
+			" AND board_milestones.user_id = gem_transactions.user_id AND board_milestones.line = gem_transactions.milestone" +
+			" AND board_milestones.revoked_at IS NULL)").
+		Find(&awards)
+	for _, award := range awards {
+		// Ledger lines are the pattern key, plus the number for rows and columns: "row 2"
+		pattern, index := *award.Milestone, 0
+		if i := strings.LastIndex(pattern, " "); i > 0 {
+			if n, err := strconv.Atoi(pattern[i+1:]); err == nil {
+				pattern, index = pattern[:i], n-1
+			}
+		}
+		milestone := models.BoardMilestone{
+			BoardID:   *award.BoardID,
+			UserID:    award.UserID,
+			Pattern:   pattern,
+			LineIndex: index,
+			Line:      *award.Milestone,
+			Gems:      award.Amount,
+			GoalID:    award.GoalID,
+			ReachedAt: award.CreatedAt,
+		}
+		if err := DB.Create(&milestone).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileGems makes User.TotalGems agree with the gem ledger. Balances from
+// before the ledger existed are carried over as an opening balance first.
+func reconcileGems() error {
+	var users []models.User
+	DB.Where("total_gems <> 0 AND NOT EXISTS (SELECT 1 FROM gem_transactions WHERE gem_transactions.user_id = users.id)").
+		Find(&users)
+	for _, u := range users {
+		entry := models.GemTransaction{
+			UserID: u.ID,
+			Amount: u.TotalGems,
+			Reason: models.GemReasonOpeningBalance,
+		}
+		if err := DB.Create(&entry).Error; err != nil {
+			return err
+		}
+	}
+
+	return DB.Model(&models.User{}).
+		Where("1 = 1").
+		Update("total_gems", gorm.Expr("(SELECT COALESCE(SUM(amount), 0) FROM gem_transactions WHERE gem_transactions.user_id = users.id)")).Error
 }
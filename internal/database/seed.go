@@ -0,0 +1,107 @@
+package database
+
+import (
+	"github.com/arnold/bingoals-api/internal/models"
+)
+
+// Seed inserts the built-in system templates. Templates are matched by title,
+// so it is safe to run on every start and new entries are added on upgrade.
+func Seed() error {
+	for _, template := range systemTemplates() {
+		template.IsSystem = true
+
+		var count int64
+		DB.Model(&models.BoardTemplate{}).
+			Where("is_system = ? AND title = ?", true, template.Title).
+			Count(&count)
+		if count > 0 {
+			continue
+		}
+		if err := DB.Create(&template).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func systemTemplates() []models.BoardTemplate {
+	return []models.BoardTemplate{
+		{
+			Title:       "Healthy Habits",
+			Description: strPtr("Small, steady changes for body and mind"),
+			Category:    strPtr("health"),
+			GridSize:    3,
+			Goals: []models.BoardTemplateGoal{
+				templateGoal(0, "Drink 2L of water daily", "💧", "sage"),
+				templateGoal(1, "Walk 10,000 steps", "🚶", "sage",
+					"Get a step tracker", "Hit 10k three days in a row", "Hit 10k every day for a month"),
+				templateGoal(2, "Sleep 8 hours", "😴", "slate"),
+				templateGoal(3, "Cook at home 5x a week", "🥗", "terracotta",
+					"Plan a weekly menu", "Learn 5 new recipes", "Meal prep on Sundays"),
+				templateGoal(4, "Run a 5K", "🏃", "sunrise",
+					"Run 1K without stopping", "Run 3K", "Sign up for a race", "Finish the 5K"),
+				templateGoal(5, "Meditate daily", "🧘", "slate"),
+				templateGoal(6, "Annual health check-up", "🩺", "sage"),
+				templateGoal(7, "Cut back on sugar", "🍎", "terracotta"),
+				templateGoal(8, "Stretch every morning", "🤸", "sunrise"),
+			},
+		},
+		{
+			Title:       "Money Moves",
+			Description: strPtr("Build savings and good money habits"),
+			Category:    strPtr("finance"),
+			GridSize:    3,
+			Goals: []models.BoardTemplateGoal{
+				templateGoal(0, "Build an emergency fund", "🛟", "slate",
+					"Save one month of expenses", "Save three months", "Save six months"),
+				templateGoal(1, "Track every expense", "📒", "sage"),
+				templateGoal(2, "Pay off a debt", "💳", "terracotta"),
+				templateGoal(3, "Start investing", "📈", "sunrise",
+					"Open an investment account", "Set up a monthly contribution"),
+				templateGoal(4, "Make a yearly budget", "🧾", "slate"),
+				templateGoal(5, "No-spend month", "🚫", "terracotta"),
+				templateGoal(6, "Cancel unused subscriptions", "✂️", "sage"),
+				templateGoal(7, "Learn about taxes", "📚", "slate"),
+				templateGoal(8, "Save for a big purchase", "🎯", "sunrise"),
+			},
+		},
+		{
+			Title:       "Adventure Year",
+			Description: strPtr("Get out and see more of the world"),
+			Category:    strPtr("travel"),
+			GridSize:    3,
+			Goals: []models.BoardTemplateGoal{
+				templateGoal(0, "Visit a new country", "✈️", "sunrise",
+					"Pick a destination", "Book flights", "Go!"),
+				templateGoal(1, "Go camping", "⛺", "sage"),
+				templateGoal(2, "Take a solo trip", "🎒", "slate"),
+				templateGoal(3, "Hike a mountain", "🏔️", "sage"),
+				templateGoal(4, "Road trip with friends", "🚗", "sunrise"),
+				templateGoal(5, "Learn basic phrases in a new language", "🗣️", "terracotta"),
+				templateGoal(6, "Try a local food festival", "🍜", "terracotta"),
+				templateGoal(7, "See the sunrise somewhere new", "🌅", "sunrise"),
+				templateGoal(8, "Explore your own city like a tourist", "🗺️", "slate"),
+			},
+		},
+	}
+}
+
+func templateGoal(position int, title, icon, mood string, miniGoals ...string) models.BoardTemplateGoal {
+	goal := models.BoardTemplateGoal{
+		Position: position,
+		Title:    title,
+		Icon:     strPtr(icon),
+		Mood:     strPtr(mood),
+	}
+	for i, mg := range miniGoals {
+		goal.MiniGoals = append(goal.MiniGoals, models.BoardTemplateMiniGoal{
+			Title:     mg,
+			SortOrder: i,
+		})
+	}
+	return goal
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,490 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/json"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// DeleteAccount permanently deletes the current user and everything they own.
+// Shared boards with other members are handed to the longest-standing member;
+// every other owned board is deleted along with its goals.
+func DeleteAccount(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var req models.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Confirm != "DELETE" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Send confirm: \"DELETE\" to delete your account",
+		})
+	}
+	if user.Password != "" {
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Incorrect password",
+			})
+		}
+	}
+
+	var uploads []string
+	transferred := map[uuid.UUID]uuid.UUID{} // boardID -> new owner
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var ownedBoards []models.Board
+		if err := tx.Unscoped().Where("user_id = ?", userID).Find(&ownedBoards).Error; err != nil {
+			return err
+		}
+
+		for _, board := range ownedBoards {
+			var heir models.BoardMember
+			hasHeir := board.BoardType == "shared" && !board.DeletedAt.Valid &&
+				tx.Where("board_id = ? AND user_id != ?", board.ID, userID).
+					Order("joined_at ASC").
+					First(&heir).Error == nil
+
+			if hasHeir {
+				if err := transferBoardOwnership(tx, &board, heir.UserID); err != nil {
+					return err
+				}
+				transferred[board.ID] = heir.UserID
+				continue
+			}
+
+			urls, err := hardDeleteBoard(tx, board.ID)
+			if err != nil {
+				return err
+			}
+			uploads = append(uploads, urls...)
+		}
+
+		// Templates the user published
+		var templateIDs []uuid.UUID
+		if err := tx.Unscoped().Model(&models.BoardTemplate{}).Where("author_id = ?", userID).Pluck("id", &templateIDs).Error; err != nil {
+			return err
+		}
+		if err := hardDeleteTemplates(tx, templateIDs); err != nil {
+			return err
+		}
+
+		// Goals on remaining boards no longer point at this user
+		if err := tx.Unscoped().Model(&models.Goal{}).Where("assigned_to = ?", userID).Update("assigned_to", nil).Error; err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Model(&models.Goal{}).Where("completed_by = ?", userID).Update("completed_by", nil).Error; err != nil {
+			return err
+		}
+
+		// Per-member progress, memberships and everything else keyed by the user
+		for _, model := range []interface{}{
+			&models.GoalMember{},
+			&models.MiniGoalMember{},
+			&models.GoalCheckIn{},
+			&models.GoalStreak{},
+			&models.GemTransaction{},
+			&models.StreakFreezeLog{},
+			&models.UserAchievement{},
+			&models.BoardMilestone{},
+			&models.BoardMember{},
+			&models.Comment{},
+			&models.Reaction{},
+			&models.Notification{},
+			&models.Activity{},
+			&models.Session{},
+			&models.OneTimeToken{},
+			&models.UserIdentity{},
+			&models.RecoveryCode{},
+		} {
+			if err := tx.Unscoped().Where("user_id = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := hardDeleteReminders(tx, "user_id = ?", userID); err != nil {
+			return err
+		}
+		if err := tx.Unscoped().Where("inviter_id = ?", userID).Delete(&models.BoardInvite{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("email = ?", normalizeEmail(user.Email)).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(&user).Error
+	})
+	if err != nil {
+		log.Printf("Failed to delete account %s: %v", userID, err)
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete account",
+		})
+	}
+
+	if user.AvatarURL != "" {
+		uploads = append(uploads, user.AvatarURL)
+	}
+	removeUnreferencedUploads(uploads)
+
+	for boardID, newOwnerID := range transferred {
+		var board models.Board
+		if database.DB.First(&board, boardID).Error != nil {
+			continue
+		}
+		announceOwnershipTransfer(board, newOwnerID, userID, newOwnerID, "previous_owner_deleted_account")
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ExportAccount streams a ZIP with the user's data as JSON plus the uploaded files it references
+func ExportAccount(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	// Boards the user owns or belongs to, including ones sitting in the trash
+	var memberBoardIDs []uuid.UUID
+	database.DB.Model(&models.BoardMember{}).Where("user_id = ?", userID).Pluck("board_id", &memberBoardIDs)
+
+	var boards []models.Board
+	query := database.DB.Unscoped().Where("user_id = ?", userID)
+	if len(memberBoardIDs) > 0 {
+		query = database.DB.Unscoped().Where("user_id = ? OR id IN ?", userID, memberBoardIDs)
+	}
+	query.Order("created_at ASC").Find(&boards)
+
+	boardIDs := make([]uuid.UUID, len(boards))
+	for i, b := range boards {
+		boardIDs[i] = b.ID
+	}
+
+	var goals []models.Goal
+	var miniGoals []models.MiniGoal
+	var reflections []models.Reflection
+	var memories []models.GoalMemory
+	var boardMembers []models.BoardMember
+	var invites []models.BoardInvite
+	if len(boardIDs) > 0 {
+		database.DB.Unscoped().Where("board_id IN ?", boardIDs).Order("board_id, position").Find(&goals)
+		database.DB.Where("board_id IN ?", boardIDs).Find(&boardMembers)
+		database.DB.Where("board_id IN ? AND inviter_id = ?", boardIDs, userID).Find(&invites)
+	}
+
+	goalIDs := make([]uuid.UUID, len(goals))
+	for i, g := range goals {
+		goalIDs[i] = g.ID
+	}
+	if len(goalIDs) > 0 {
+		database.DB.Unscoped().Where("goal_id IN ?", goalIDs).Order("created_at ASC").Find(&miniGoals)
+		database.DB.Unscoped().Where("goal_id IN ?", goalIDs).Find(&reflections)
+		database.DB.Unscoped().Where("goal_id IN ?", goalIDs).Order("created_at ASC").Find(&memories)
+	}
+
+	var goalMembers []models.GoalMember
+	var miniGoalMembers []models.MiniGoalMember
+	var comments []models.Comment
+	var reactions []models.Reaction
+	var notifications []models.Notification
+	var activities []models.Activity
+	var sessions []models.Session
+	var identities []models.UserIdentity
+	var recoveryCodes []models.RecoveryCode
+	var loginAttempts []models.LoginAttempt
+	var templates []models.BoardTemplate
+	var reminders []models.ReminderRule
+	var checkIns []models.GoalCheckIn
+	var streaks []models.GoalStreak
+	var gemTransactions []models.GemTransaction
+	var streakFreezes []models.StreakFreezeLog
+	var achievements []models.UserAchievement
+	var milestones []models.BoardMilestone
+	database.DB.Where("user_id = ?", userID).Find(&goalMembers)
+	database.DB.Where("user_id = ?", userID).Find(&miniGoalMembers)
+	database.DB.Where("user_id = ?", userID).Order("checked_in_at ASC").Find(&checkIns)
+	database.DB.Where("user_id = ?", userID).Find(&streaks)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&gemTransactions)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&streakFreezes)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&achievements)
+	database.DB.Where("user_id = ?", userID).Order("reached_at ASC").Find(&milestones)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&comments)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&reactions)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&notifications)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&activities)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&sessions)
+	database.DB.Where("user_id = ?", userID).Find(&identities)
+	database.DB.Where("user_id = ?", userID).Find(&recoveryCodes)
+	database.DB.Where("email = ?", normalizeEmail(user.Email)).Order("created_at ASC").Find(&loginAttempts)
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&reminders)
+	database.DB.Where("author_id = ?", userID).Preload("Goals.MiniGoals").Order("created_at ASC").Find(&templates)
+
+	profile := meResponse(user)
+
+	files := []exportFile{
+		{"profile.json", profile},
+		{"identities.json", identities},
+		{"sessions.json", sessions},
+		{"recovery_codes.json", recoveryCodes},
+		{"login_attempts.json", loginAttempts},
+		{"boards.json", boards},
+		{"board_members.json", boardMembers},
+		{"board_invites.json", invites},
+		{"goals.json", goals},
+		{"mini_goals.json", miniGoals},
+		{"reflections.json", reflections},
+		{"goal_memories.json", memories},
+		{"goal_members.json", goalMembers},
+		{"mini_goal_members.json", miniGoalMembers},
+		{"goal_check_ins.json", checkIns},
+		{"goal_streaks.json", streaks},
+		{"gem_transactions.json", gemTransactions},
+		{"streak_freezes.json", streakFreezes},
+		{"achievements.json", achievements},
+		{"board_milestones.json", milestones},
+		{"comments.json", comments},
+		{"reactions.json", reactions},
+		{"notifications.json", notifications},
+		{"activities.json", activities},
+		{"board_templates.json", templates},
+		{"reminders.json", reminders},
+	}
+
+	// Collect referenced uploads
+	var uploads []string
+	uploads = append(uploads, user.AvatarURL)
+	for _, g := range goals {
+		if g.ImageURL != nil {
+			uploads = append(uploads, *g.ImageURL)
+		}
+	}
+	for _, mg := range miniGoals {
+		if mg.ImageURL != nil {
+			uploads = append(uploads, *mg.ImageURL)
+		}
+	}
+	for _, m := range memories {
+		uploads = append(uploads, m.ImageURL)
+	}
+
+	filename := "bingoals-export-" + time.Now().Format("20060102") + ".zip"
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="`+filename+`"`)
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		zw := zip.NewWriter(w)
+		if err := writeExport(zw, files, uploads); err != nil {
+			log.Printf("Export for user %s failed: %v", userID, err)
+		}
+		if err := zw.Close(); err != nil {
+			log.Printf("Export for user %s failed to finalize: %v", userID, err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
+
+type exportFile struct {
+	name string
+	data interface{}
+}
+
+func writeExport(zw *zip.Writer, files []exportFile, uploads []string) error {
+	now := time.Now()
+	create := func(name string) (io.Writer, error) {
+		return zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: now})
+	}
+
+	for _, f := range files {
+		w, err := create(f.name)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(f.data); err != nil {
+			return err
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, url := range uploads {
+		path, ok := uploadPath(url)
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		src, err := os.Open(path)
+		if err != nil {
+			continue // file was removed from disk; the JSON still records the URL
+		}
+		w, err := create("uploads/" + filepath.Base(path))
+		if err == nil {
+			_, err = io.Copy(w, src)
+		}
+		src.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hardDeleteBoard permanently removes a board and everything hanging off it.
+// Returns the upload URLs that were referenced by the deleted rows.
+func hardDeleteBoard(tx *gorm.DB, boardID uuid.UUID) ([]string, error) {
+	var goalIDs []uuid.UUID
+	tx.Unscoped().Model(&models.Goal{}).Where("board_id = ?", boardID).Pluck("id", &goalIDs)
+
+	uploads, err := hardDeleteGoals(tx, goalIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, model := range []interface{}{
+		&models.Activity{},
+		&models.BoardInvite{},
+		&models.BoardMember{},
+		&models.BoardMilestone{},
+	} {
+		if err := tx.Unscoped().Where("board_id = ?", boardID).Delete(model).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	// Later years rolled over from this board lose the link, not the board
+	if err := tx.Unscoped().Model(&models.Board{}).Where("source_board_id = ?", boardID).Update("source_board_id", nil).Error; err != nil {
+		return nil, err
+	}
+
+	if err := tx.Unscoped().Where("id = ?", boardID).Delete(&models.Board{}).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// hardDeleteGoals permanently removes goals with their mini-goals, reflections,
+// memories, per-member state, comments and reactions.
+func hardDeleteGoals(tx *gorm.DB, goalIDs []uuid.UUID) ([]string, error) {
+	if len(goalIDs) == 0 {
+		return nil, nil
+	}
+
+	var uploads []string
+	var imageURLs []*string
+	tx.Unscoped().Model(&models.Goal{}).Where("id IN ?", goalIDs).Pluck("image_url", &imageURLs)
+	var miniImageURLs []*string
+	tx.Unscoped().Model(&models.MiniGoal{}).Where("goal_id IN ?", goalIDs).Pluck("image_url", &miniImageURLs)
+	for _, u := range append(imageURLs, miniImageURLs...) {
+		if u != nil {
+			uploads = append(uploads, *u)
+		}
+	}
+	var memoryURLs []string
+	tx.Unscoped().Model(&models.GoalMemory{}).Where("goal_id IN ?", goalIDs).Pluck("image_url", &memoryURLs)
+	uploads = append(uploads, memoryURLs...)
+
+	var miniGoalIDs []uuid.UUID
+	tx.Unscoped().Model(&models.MiniGoal{}).Where("goal_id IN ?", goalIDs).Pluck("id", &miniGoalIDs)
+	if len(miniGoalIDs) > 0 {
+		if err := tx.Unscoped().Where("mini_goal_id IN ?", miniGoalIDs).Delete(&models.MiniGoalMember{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := hardDeleteReminders(tx, "goal_id IN ?", goalIDs); err != nil {
+		return nil, err
+	}
+
+	for _, model := range []interface{}{
+		&models.MiniGoal{},
+		&models.Reflection{},
+		&models.GoalMemory{},
+		&models.GoalMember{},
+		&models.GoalCheckIn{},
+		&models.GoalStreak{},
+		&models.Comment{},
+		&models.Reaction{},
+	} {
+		if err := tx.Unscoped().Where("goal_id IN ?", goalIDs).Delete(model).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Unscoped().Where("id IN ?", goalIDs).Delete(&models.Goal{}).Error; err != nil {
+		return nil, err
+	}
+	return uploads, nil
+}
+
+// removeUnreferencedUploads deletes local upload files that no remaining row points at.
+// Copied boards can share an image, so each file is checked before removal.
+func removeUnreferencedUploads(urls []string) {
+	seen := map[string]bool{}
+	for _, url := range urls {
+		path, ok := uploadPath(url)
+		if !ok || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		var refs int64
+		var count int64
+		database.DB.Unscoped().Model(&models.Goal{}).Where("image_url = ?", url).Count(&count)
+		refs += count
+		database.DB.Unscoped().Model(&models.MiniGoal{}).Where("image_url = ?", url).Count(&count)
+		refs += count
+		database.DB.Unscoped().Model(&models.GoalMemory{}).Where("image_url = ?", url).Count(&count)
+		refs += count
+		database.DB.Unscoped().Model(&models.User{}).Where("avatar_url = ?", url).Count(&count)
+		refs += count
+		if refs > 0 {
+			continue
+		}
+
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to remove upload %s: %v", path, err)
+		}
+	}
+}
+
+// uploadPath maps an "/uploads/<file>" URL to its path on disk
+func uploadPath(url string) (string, bool) {
+	if !strings.HasPrefix(url, "/uploads/") {
+		return "", false
+	}
+	name := filepath.Base(url)
+	if name == "." || name == "/" || name == "" {
+		return "", false
+	}
+	return filepath.Join("uploads", name), true
+}
@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/google/uuid"
+)
+
+// Stats achievements are measured against
+const (
+	metricGoalsCompleted = "goals_completed"
+	metricMilestones     = "milestones"
+	metricBlackouts      = "blackouts"
+	metricDailyStreak    = "daily_streak"
+	metricReflections    = "reflections"
+	metricComments       = "comments"
+	metricBoardsShared   = "boards_shared"
+)
+
+// achievement is an entry in the catalogue: earned once the metric reaches the threshold
+type achievement struct {
+	Key         string
+	Title       string
+	Description string
+	Metric      string
+	Threshold   int
+}
+
+// achievementCatalogue lists every achievement. Keys are stored on
+// UserAchievement, so don't rename them.
+var achievementCatalogue = []achievement{
+	{"first_goal", "First step", "Complete your first goal", metricGoalsCompleted, 1},
+	{"goals_25", "Go-getter", "Complete 25 goals", metricGoalsCompleted, 25},
+	{"goals_100", "Centurion", "Complete 100 goals", metricGoalsCompleted, 100},
+	{"first_bingo", "Bingo!", "Finish your first row, column, diagonal or other milestone", metricMilestones, 1},
+	{"first_blackout", "Blackout", "Complete every square on a board", metricBlackouts, 1},
+	{"streak_7", "On a roll", "Keep a 7-day streak", metricDailyStreak, 7},
+	{"streak_30", "Unstoppable", "Keep a 30-day streak", metricDailyStreak, 30},
+	{"streak_100", "Habit formed", "Keep a 100-day streak", metricDailyStreak, 100},
+	{"reflections_10", "Thoughtful", "Write ten reflections", metricReflections, 10},
+	{"comments_10", "Cheerleader", "Leave ten comments on goals", metricComments, 10},
+	{"boards_shared_5", "Team player", "Share five boards with others", metricBoardsShared, 5},
+}
+
+// achievementMetrics measures each stat for a user
+var achievementMetrics = map[string]func(userID uuid.UUID) int{
+	metricGoalsCompleted: countGoalsCompleted,
+	metricMilestones: func(userID uuid.UUID) int {
+		return countLiveAwards(userID, "")
+	},
+	metricBlackouts: func(userID uuid.UUID) int {
+		return countLiveAwards(userID, "blackout")
+	},
+	metricDailyStreak: func(userID uuid.UUID) int {
+		var user models.User
+		if err := database.DB.First(&user, userID).Error; err != nil {
+			return 0
+		}
+		return liveDailyStreak(&user, time.Now())
+	},
+	metricReflections: countReflections,
+	metricComments: func(userID uuid.UUID) int {
+		var n int64
+		database.DB.Model(&models.Comment{}).Where("user_id = ?", userID).Count(&n)
+		return int(n)
+	},
+	metricBoardsShared: func(userID uuid.UUID) int {
+		var n int64
+		database.DB.Model(&models.Board{}).
+			Where("user_id = ? AND board_type = ?", userID, "shared").
+			Where("EXISTS (SELECT 1 FROM board_members WHERE board_members.board_id = boards.id AND board_members.user_id <> ?)", userID).
+			Count(&n)
+		return int(n)
+	},
+}
+
+// EarnedAchievement is an achievement as shown on profiles
+type EarnedAchievement struct {
+	Key         string    `json:"key"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	AwardedAt   time.Time `json:"awardedAt"`
+}
+
+// evaluateAchievements awards any achievement measured by one of the given
+// metrics that the user has now reached, and notifies them. Call it after the
+// event that moves those metrics.
+func evaluateAchievements(userID uuid.UUID, metrics ...string) []EarnedAchievement {
+	var earnedKeys []string
+	database.DB.Model(&models.UserAchievement{}).Where("user_id = ?", userID).Pluck("achievement_key", &earnedKeys)
+	earned := make(map[string]bool, len(earnedKeys))
+	for _, key := range earnedKeys {
+		earned[key] = true
+	}
+
+	wanted := make(map[string]bool, len(metrics))
+	for _, m := range metrics {
+		wanted[m] = true
+	}
+
+	values := map[string]int{}
+	awarded := []EarnedAchievement{}
+	for _, a := range achievementCatalogue {
+		if earned[a.Key] || !wanted[a.Metric] {
+			continue
+		}
+		value, ok := values[a.Metric]
+		if !ok {
+			value = achievementMetrics[a.Metric](userID)
+			values[a.Metric] = value
+		}
+		if value < a.Threshold {
+			continue
+		}
+
+		record := models.UserAchievement{UserID: userID, AchievementKey: a.Key}
+		if err := database.DB.Create(&record).Error; err != nil {
+			// Someone else just awarded it
+			continue
+		}
+		awarded = append(awarded, EarnedAchievement{a.Key, a.Title, a.Description, record.CreatedAt})
+
+		CreateNotification(userID, "achievement_unlocked",
+			"Achievement unlocked!",
+			"You earned \""+a.Title+"\": "+a.Description,
+			map[string]interface{}{"achievement": a.Key},
+		)
+	}
+	return awarded
+}
+
+// userAchievements lists what a user has earned, oldest first. Keys no longer
+// in the catalogue are left out.
+func userAchievements(userID uuid.UUID) []EarnedAchievement {
+	var records []models.UserAchievement
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&records)
+
+	byKey := make(map[string]achievement, len(achievementCatalogue))
+	for _, a := range achievementCatalogue {
+		byKey[a.Key] = a
+	}
+
+	result := []EarnedAchievement{}
+	for _, r := range records {
+		if a, ok := byKey[r.AchievementKey]; ok {
+			result = append(result, EarnedAchievement{a.Key, a.Title, a.Description, r.CreatedAt})
+		}
+	}
+	return result
+}
+
+// countGoalsCompleted counts goals the user has completed: squares on their
+// personal boards and their own completions on shared boards
+func countGoalsCompleted(userID uuid.UUID) int {
+	var personal int64
+	database.DB.Model(&models.Goal{}).
+		Joins("JOIN boards ON boards.id = goals.board_id AND boards.deleted_at IS NULL").
+		Where("boards.user_id = ? AND boards.board_type <> ?", userID, "shared").
+		Where("goals.status = ? AND goals.is_grace_square = ?", "completed", false).
+		Count(&personal)
+
+	var shared int64
+	database.DB.Model(&models.GoalMember{}).
+		Joins("JOIN goals ON goals.id = goal_members.goal_id AND goals.deleted_at IS NULL").
+		Joins("JOIN boards ON boards.id = goals.board_id AND boards.deleted_at IS NULL").
+		Where("goal_members.user_id = ? AND goal_members.is_completed = ?", userID, true).
+		Count(&shared)
+
+	return int(personal + shared)
+}
+
+// countLiveAwards counts the user's milestone awards still standing on the
+// gem ledger, optionally only one kind of milestone
+func countLiveAwards(userID uuid.UUID, milestone string) int {
+	query := database.DB.Model(&models.GemTransaction{}).
+		Where("user_id = ? AND reason = ? AND reversed_at IS NULL", userID, models.GemReasonMilestone)
+	if milestone != "" {
+		query = query.Where("milestone = ?", milestone)
+	}
+	var n int64
+	query.Count(&n)
+	return int(n)
+}
+
+// countReflections counts reflections with something written in them on the
+// user's own personal boards. Reflections on shared boards can be written by
+// any editor and don't record who, so they don't count for anyone.
+func countReflections(userID uuid.UUID) int {
+	var n int64
+	database.DB.Model(&models.Reflection{}).
+		Joins("JOIN goals ON goals.id = reflections.goal_id AND goals.deleted_at IS NULL").
+		Joins("JOIN boards ON boards.id = goals.board_id AND boards.deleted_at IS NULL").
+		Where("boards.user_id = ? AND boards.board_type <> ?", userID, "shared").
+		Where("COALESCE(reflections.obstacles, '') <> '' OR COALESCE(reflections.victories, '') <> '' OR COALESCE(reflections.notes, '') <> '' OR COALESCE(reflections.reflection_answer, '') <> ''").
+		Count(&n)
+	return int(n)
+}
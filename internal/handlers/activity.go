@@ -21,10 +21,8 @@ func GetBoardActivity(c *fiber.Ctx) error {
 		})
 	}
 
-	if !isBoardMember(boardID, userID) {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	if _, _, err := checkBoardPermission(boardID, userID, permViewBoard); err != nil {
+		return err
 	}
 
 	// Pagination
@@ -89,10 +87,8 @@ func AddReaction(c *fiber.Ctx) error {
 		})
 	}
 
-	if !isBoardMember(goal.BoardID, userID) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have access to this board",
-		})
+	if _, _, err := checkBoardPermission(goal.BoardID, userID, permInteract); err != nil {
+		return err
 	}
 
 	// Toggle: if same reaction exists, remove it; otherwise add it
@@ -155,10 +151,8 @@ func GetGoalReactions(c *fiber.Ctx) error {
 		})
 	}
 
-	if !isBoardMember(goal.BoardID, userID) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have access to this board",
-		})
+	if _, _, err := checkBoardPermission(goal.BoardID, userID, permViewBoard); err != nil {
+		return err
 	}
 
 	var reactions []models.Reaction
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"sort"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// AssignedGoal is returned by the GET /api/me/assigned-goals endpoint
+type AssignedGoal struct {
+	models.Goal
+	BoardTitle string `json:"boardTitle"`
+	BoardYear  int    `json:"boardYear"`
+}
+
+// GetAssignedGoals lists the goals assigned to the current user across every
+// board they can still see, open goals first and soonest due first
+func GetAssignedGoals(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var goals []models.Goal
+	database.DB.
+		Joins("JOIN boards ON boards.id = goals.board_id AND boards.deleted_at IS NULL").
+		Where("goals.assigned_to = ?", userID).
+		Find(&goals)
+
+	boards := map[uuid.UUID]*models.Board{}
+	byBoard := map[uuid.UUID][]models.Goal{}
+	for _, g := range goals {
+		if _, ok := boards[g.BoardID]; !ok {
+			board, _, err := checkBoardPermission(g.BoardID, userID, permViewBoard)
+			if err != nil {
+				boards[g.BoardID] = nil
+				continue
+			}
+			boards[g.BoardID] = board
+		}
+		if boards[g.BoardID] != nil {
+			byBoard[g.BoardID] = append(byBoard[g.BoardID], g)
+		}
+	}
+
+	items := []AssignedGoal{}
+	for boardID, boardGoals := range byBoard {
+		board := boards[boardID]
+		overlayMemberStatus(boardGoals, board.BoardType, userID)
+		for _, g := range boardGoals {
+			items = append(items, AssignedGoal{Goal: g, BoardTitle: board.Title, BoardYear: board.Year})
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		a, b := items[i], items[j]
+		if a.IsCompleted != b.IsCompleted {
+			return !a.IsCompleted
+		}
+		if (a.DueDate == nil) != (b.DueDate == nil) {
+			return a.DueDate != nil
+		}
+		if a.DueDate != nil && !a.DueDate.Equal(*b.DueDate) {
+			return a.DueDate.Before(*b.DueDate)
+		}
+		if a.BoardTitle != b.BoardTitle {
+			return a.BoardTitle < b.BoardTitle
+		}
+		return a.Position < b.Position
+	})
+
+	return c.JSON(items)
+}
+
+// parseAssignee resolves UpdateGoalRequest.AssignedTo: nil for "", otherwise a
+// member of the shared board who can track progress
+func parseAssignee(board *models.Board, value string) (*uuid.UUID, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if board.BoardType != "shared" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Goals can only be assigned on shared boards")
+	}
+	assigneeID, err := uuid.Parse(value)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Invalid assignee ID")
+	}
+	role := boardRole(board, assigneeID)
+	if role == "" {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Assignee is not a member of this board")
+	}
+	if !roleAllows(role, permTrackProgress) {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "Viewers can't be assigned goals")
+	}
+	return &assigneeID, nil
+}
+
+// sameUserID compares two optional user IDs
+func sameUserID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// announceAssignment logs, notifies and broadcasts a change of assignee.
+// previous is who the goal was assigned to before, if anyone.
+func announceAssignment(board *models.Board, goal *models.Goal, actorID uuid.UUID, previous *uuid.UUID) {
+	goalTitle := ""
+	if goal.Title != nil {
+		goalTitle = *goal.Title
+	}
+
+	LogActivity(board.ID, actorID, "goal_assigned", &goal.ID, map[string]interface{}{
+		"goalTitle":    goalTitle,
+		"position":     goal.Position,
+		"assignedTo":   goal.AssignedTo,
+		"assignedFrom": previous,
+	})
+
+	metadata := map[string]interface{}{
+		"boardId":  board.ID.String(),
+		"goalId":   goal.ID.String(),
+		"position": goal.Position,
+	}
+	if goal.AssignedTo != nil && *goal.AssignedTo != actorID {
+		var actor models.User
+		database.DB.First(&actor, actorID)
+		name := actor.DisplayName
+		if name == "" {
+			name = actor.Name
+		}
+		CreateNotification(*goal.AssignedTo, "goal_assigned",
+			"New goal assigned",
+			name+" assigned you \""+goalTitle+"\" on "+board.Title,
+			metadata,
+		)
+	}
+	if previous != nil && *previous != actorID {
+		CreateNotification(*previous, "goal_unassigned",
+			"Goal reassigned",
+			"You're no longer assigned \""+goalTitle+"\" on "+board.Title,
+			metadata,
+		)
+	}
+
+	WS.Broadcast(board.ID, actorID, WSEvent{
+		Type:    EventGoalAssigned,
+		BoardID: board.ID.String(),
+		UserID:  actorID.String(),
+		Data: map[string]interface{}{
+			"goalId":     goal.ID.String(),
+			"position":   goal.Position,
+			"assignedTo": goal.AssignedTo,
+		},
+	})
+}
+
+// unassignMemberGoals clears assignments held by someone who left a board
+func unassignMemberGoals(boardID, userID uuid.UUID) {
+	database.DB.Model(&models.Goal{}).
+		Where("board_id = ? AND assigned_to = ?", boardID, userID).
+		Update("assigned_to", nil)
+}
+
+// syncCompletedBy keeps Goal.CompletedBy pointing at someone who has actually
+// completed the goal, so reactions and comments notify the right person.
+// On personal boards that's whoever completed it (the owner if unknown); on
+// shared boards the first member to complete it, or the assignee when they
+// have. actorID is the user whose action may have changed completion.
+func syncCompletedBy(goalID uuid.UUID, actorID *uuid.UUID) *uuid.UUID {
+	var goal models.Goal
+	if err := database.DB.First(&goal, goalID).Error; err != nil {
+		return nil
+	}
+	var board models.Board
+	if err := database.DB.First(&board, goal.BoardID).Error; err != nil {
+		return goal.CompletedBy
+	}
+
+	completedBy := goal.CompletedBy
+	if board.BoardType != "shared" {
+		switch {
+		case !goal.IsCompleted:
+			completedBy = nil
+		case completedBy == nil && actorID != nil:
+			completedBy = actorID
+		case completedBy == nil:
+			completedBy = &board.UserID
+		}
+	} else {
+		var completers []models.GoalMember
+		database.DB.Where("goal_id = ? AND is_completed = true", goalID).Order("completed_at ASC").Find(&completers)
+		done := make(map[uuid.UUID]bool, len(completers))
+		for _, gm := range completers {
+			done[gm.UserID] = true
+		}
+
+		switch {
+		case goal.AssignedTo != nil && done[*goal.AssignedTo]:
+			completedBy = goal.AssignedTo
+		case completedBy != nil && done[*completedBy]:
+			// still accurate
+		case len(completers) > 0:
+			completedBy = &completers[0].UserID
+		default:
+			completedBy = nil
+		}
+	}
+
+	if !sameUserID(completedBy, goal.CompletedBy) {
+		database.DB.Model(&goal).Update("completed_by", completedBy)
+	}
+	return completedBy
+}
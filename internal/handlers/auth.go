@@ -1,16 +1,16 @@
 package handlers
 
 import (
-	"encoding/json"
-	"fmt"
+	"errors"
 	"log"
-	"net/http"
-	"os"
-	"strings"
+	"math"
+	"strconv"
+	"time"
 
 	"github.com/arnold/bingoals-api/internal/database"
 	"github.com/arnold/bingoals-api/internal/middleware"
 	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
@@ -59,18 +59,17 @@ func Register(c *fiber.Ctx) error {
 		})
 	}
 
-	// Generate token
-	token, err := middleware.GenerateToken(user.ID, user.Email)
+	go sendVerificationEmail(user)
+
+	// Start a session
+	resp, err := issueSession(c, user)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to generate token",
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(models.AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	return c.Status(fiber.StatusCreated).JSON(resp)
 }
 
 func Login(c *fiber.Ctx) error {
@@ -88,9 +87,18 @@ func Login(c *fiber.Ctx) error {
 		})
 	}
 
+	// Refuse early while the email is locked out after repeated failures
+	if wait := loginLockedFor(req.Email); wait > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+		return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+			"error": "Too many failed login attempts. Please try again later.",
+		})
+	}
+
 	// Find user
 	var user models.User
 	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		recordLoginAttempt(c, req.Email, false)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid credentials",
 		})
@@ -98,23 +106,15 @@ func Login(c *fiber.Ctx) error {
 
 	// Check password
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+		recordLoginAttempt(c, req.Email, false)
 		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 			"error": "Invalid credentials",
 		})
 	}
+	recordLoginAttempt(c, req.Email, true)
 
-	// Generate token
-	token, err := middleware.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
-		})
-	}
-
-	return c.JSON(models.AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	// Start a session, or pause for the second factor
+	return completeLogin(c, user)
 }
 
 func GetMe(c *fiber.Ctx) error {
@@ -127,21 +127,7 @@ func GetMe(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"id":             user.ID,
-		"email":          user.Email,
-		"authProvider":   user.AuthProvider,
-		"name":           user.Name,
-		"displayName":    user.DisplayName,
-		"avatarUrl":      user.AvatarURL,
-		"bio":            user.Bio,
-		"dailyStreak":    user.DailyStreak,
-		"totalGems":      user.TotalGems,
-		"lastActiveDate": user.LastActiveDate,
-		"level":          user.Level(),
-		"createdAt":      user.CreatedAt,
-		"updatedAt":      user.UpdatedAt,
-	})
+	return c.JSON(meResponse(user))
 }
 
 func UpdateProfile(c *fiber.Ctx) error {
@@ -173,6 +159,16 @@ func UpdateProfile(c *fiber.Ctx) error {
 	if req.Name != nil {
 		user.Name = *req.Name
 	}
+	timezoneChanged := false
+	if req.Timezone != nil && *req.Timezone != user.Timezone {
+		if _, err := time.LoadLocation(*req.Timezone); err != nil || *req.Timezone == "" || *req.Timezone == "Local" {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Timezone must be an IANA name like Europe/Berlin",
+			})
+		}
+		user.Timezone = *req.Timezone
+		timezoneChanged = true
+	}
 
 	if err := database.DB.Save(&user).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -180,21 +176,39 @@ func UpdateProfile(c *fiber.Ctx) error {
 		})
 	}
 
-	return c.JSON(fiber.Map{
-		"id":             user.ID,
-		"email":          user.Email,
-		"authProvider":   user.AuthProvider,
-		"name":           user.Name,
-		"displayName":    user.DisplayName,
-		"avatarUrl":      user.AvatarURL,
-		"bio":            user.Bio,
-		"dailyStreak":    user.DailyStreak,
-		"totalGems":      user.TotalGems,
-		"lastActiveDate": user.LastActiveDate,
-		"level":          user.Level(),
-		"createdAt":      user.CreatedAt,
-		"updatedAt":      user.UpdatedAt,
-	})
+	if timezoneChanged {
+		rescheduleReminders("user_id = ?", user.ID)
+	}
+
+	return c.JSON(meResponse(user))
+}
+
+// meResponse is the private view of the current user returned by /api/me
+func meResponse(user models.User) fiber.Map {
+	now := time.Now()
+	providers := loginProviders(user)
+	return fiber.Map{
+		"id":              user.ID,
+		"email":           user.Email,
+		"emailVerifiedAt": user.EmailVerifiedAt,
+		"authProvider":    legacyAuthProvider(providers), // deprecated, use authProviders
+		"authProviders":   providers,
+		"mfaEnabled":      user.MFAEnabled(),
+		"name":            user.Name,
+		"displayName":     user.DisplayName,
+		"avatarUrl":       user.AvatarURL,
+		"bio":             user.Bio,
+		"dailyStreak":     liveDailyStreak(&user, now),
+		"streakBreaksAt":  streakBreaksAt(&user, now),
+		"streakFreezes":   user.StreakFreezes,
+		"totalGems":       user.TotalGems,
+		"lastActiveDate":  user.LastActiveDate,
+		"timezone":        user.Timezone,
+		"level":           user.Level(),
+		"achievements":    userAchievements(user.ID),
+		"createdAt":       user.CreatedAt,
+		"updatedAt":       user.UpdatedAt,
+	}
 }
 
 func GetUserProfile(c *fiber.Ctx) error {
@@ -214,25 +228,16 @@ func GetUserProfile(c *fiber.Ctx) error {
 
 	// Return limited public profile (no email, no streak internals)
 	return c.JSON(fiber.Map{
-		"id":          user.ID,
-		"name":        user.Name,
-		"displayName": user.DisplayName,
-		"avatarUrl":   user.AvatarURL,
-		"bio":         user.Bio,
-		"level":       user.Level(),
+		"id":           user.ID,
+		"name":         user.Name,
+		"displayName":  user.DisplayName,
+		"avatarUrl":    user.AvatarURL,
+		"bio":          user.Bio,
+		"level":        user.Level(),
+		"achievements": userAchievements(user.ID),
 	})
 }
 
-// googleTokenInfo represents the response from Google's tokeninfo endpoint
-type googleTokenInfo struct {
-	Aud           string `json:"aud"`
-	Email         string `json:"email"`
-	EmailVerified string `json:"email_verified"`
-	Name          string `json:"name"`
-	Picture       string `json:"picture"`
-	Sub           string `json:"sub"`
-}
-
 func GoogleLogin(c *fiber.Ctx) error {
 	var req models.GoogleAuthRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -247,32 +252,10 @@ func GoogleLogin(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify the Google ID token
-	tokenInfo, err := verifyGoogleIDToken(req.IDToken)
+	// Verify the Google ID token locally against Google's signing keys
+	tokenInfo, err := services.Google.Verify(req.IDToken)
 	if err != nil {
-		log.Printf("Google token verification failed: %v", err)
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "Invalid Google token",
-		})
-	}
-
-	// Verify the audience matches one of our client IDs (comma-separated).
-	// The token's aud will be the iOS client ID when signing in from iOS,
-	// or the web client ID from other platforms.
-	allowedIDs := os.Getenv("GOOGLE_CLIENT_IDS")
-	if allowedIDs != "" {
-		valid := false
-		for _, id := range strings.Split(allowedIDs, ",") {
-			if strings.TrimSpace(id) == tokenInfo.Aud {
-				valid = true
-				break
-			}
-		}
-		if !valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Token not intended for this app",
-			})
-		}
+		return googleVerifyError(c, err)
 	}
 
 	if tokenInfo.Email == "" {
@@ -281,53 +264,71 @@ func GoogleLogin(c *fiber.Ctx) error {
 		})
 	}
 
-	// Find or create user by email
 	var user models.User
-	err = database.DB.Where("email = ?", tokenInfo.Email).First(&user).Error
-	if err != nil {
-		// User doesn't exist — create new account
+
+	// 1. A Google account we've seen before signs straight into its linked user
+	var identity models.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", models.ProviderGoogle, tokenInfo.Subject).First(&identity).Error; err == nil {
+		if err := database.DB.First(&user, identity.UserID).Error; err != nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error": "Linked account no longer exists",
+			})
+		}
+	} else if err := database.DB.Where("email = ?", tokenInfo.Email).First(&user).Error; err == nil {
+		// 2. An account with the same email exists. Only link automatically when
+		// that account's email has been verified (or it has no password, i.e. it
+		// was created through Google before identities were recorded). Otherwise
+		// whoever registered the address could keep password access to it.
+		if user.EmailVerifiedAt == nil && user.Password != "" {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "An account with this email already exists. Log in with your password and link Google from your profile.",
+				"code":  "account_link_required",
+			})
+		}
+
+		if err := linkIdentity(&user, models.ProviderGoogle, tokenInfo.Subject, tokenInfo.Email); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to link Google account",
+			})
+		}
+	} else {
+		// 3. New user
+		now := time.Now()
 		user = models.User{
-			Email:        tokenInfo.Email,
-			Name:         tokenInfo.Name,
-			AuthProvider: "google",
+			Email:           tokenInfo.Email,
+			EmailVerifiedAt: &now,
+			Name:            tokenInfo.Name,
 		}
 		if err := database.DB.Create(&user).Error; err != nil {
 			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 				"error": "Failed to create user",
 			})
 		}
+		if err := linkIdentity(&user, models.ProviderGoogle, tokenInfo.Subject, tokenInfo.Email); err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to link Google account",
+			})
+		}
 	}
 
-	// Generate JWT
-	token, err := middleware.GenerateToken(user.ID, user.Email)
-	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to generate token",
-		})
-	}
-
-	return c.JSON(models.AuthResponse{
-		Token: token,
-		User:  user,
-	})
+	// Start a session, or pause for the second factor
+	return completeLogin(c, user)
 }
 
-// verifyGoogleIDToken verifies a Google ID token using Google's tokeninfo endpoint
-func verifyGoogleIDToken(idToken string) (*googleTokenInfo, error) {
-	resp, err := http.Get("https://oauth2.googleapis.com/tokeninfo?id_token=" + idToken)
-	if err != nil {
-		return nil, fmt.Errorf("failed to verify token: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("token verification failed with status %d", resp.StatusCode)
-	}
-
-	var info googleTokenInfo
-	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
-		return nil, fmt.Errorf("failed to decode token info: %w", err)
+// googleVerifyError maps a Google token verification failure to a response
+func googleVerifyError(c *fiber.Ctx, err error) error {
+	log.Printf("Google token verification failed: %v", err)
+	switch {
+	case errors.Is(err, services.ErrGoogleAudience):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Token not intended for this app",
+		})
+	case errors.Is(err, services.ErrGoogleEmailUnverified):
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Google account email is not verified",
+		})
 	}
-
-	return &info, nil
+	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+		"error": "Invalid Google token",
+	})
 }
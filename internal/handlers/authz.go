@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// boardPermission is an action on a board that requires a minimum role
+type boardPermission string
+
+const (
+	permViewBoard     boardPermission = "view_board"     // read the board, goals, members, activity
+	permInteract      boardPermission = "interact"       // comment and react
+	permTrackProgress boardPermission = "track_progress" // complete goals and mini-goals
+	permEditGoals     boardPermission = "edit_goals"     // change squares, mini-goals, memories, reflections
+	permManageInvites boardPermission = "manage_invites" // create invite codes
+	permManageMembers boardPermission = "manage_members" // remove members, change roles
+	permModerate      boardPermission = "moderate"       // delete other people's comments
+	permEditBoard     boardPermission = "edit_board"     // rename the board
+	permDeleteBoard   boardPermission = "delete_board"   // delete the board
+	permTransferBoard boardPermission = "transfer_board" // hand the board to another member
+	permRolloverBoard boardPermission = "rollover_board" // start next year's board from this one
+)
+
+// permissionMinRole is the single source of truth for who may do what
+var permissionMinRole = map[boardPermission]string{
+	permViewBoard:     models.RoleViewer,
+	permInteract:      models.RoleViewer,
+	permTrackProgress: models.RoleEditor,
+	permEditGoals:     models.RoleEditor,
+	permManageInvites: models.RoleAdmin,
+	permManageMembers: models.RoleAdmin,
+	permModerate:      models.RoleAdmin,
+	permEditBoard:     models.RoleAdmin,
+	permDeleteBoard:   models.RoleOwner,
+	permTransferBoard: models.RoleOwner,
+	permRolloverBoard: models.RoleOwner,
+}
+
+// roleAllows reports whether a role grants a permission. An empty role (not a member) allows nothing.
+func roleAllows(role string, perm boardPermission) bool {
+	min, ok := permissionMinRole[perm]
+	if !ok || role == "" {
+		return false
+	}
+	return models.RoleRank(role) >= models.RoleRank(min)
+}
+
+// boardRole returns the user's role on a board, or "" if they aren't a member.
+// Board.UserID is authoritative for ownership.
+func boardRole(board *models.Board, userID uuid.UUID) string {
+	if board.UserID == userID {
+		return models.RoleOwner
+	}
+
+	var member models.BoardMember
+	if err := database.DB.Where("board_id = ? AND user_id = ?", board.ID, userID).First(&member).Error; err != nil {
+		return ""
+	}
+	if member.Role == models.RoleOwner {
+		// Stale row from before a transfer; the board says someone else owns it
+		return models.RoleAdmin
+	}
+	return member.Role
+}
+
+// authorizeBoard loads a board and checks the current user holds perm on it.
+// Non-members get 404 so board IDs can't be probed; members without the
+// permission get 403. Returns the caller's role on success.
+func authorizeBoard(c *fiber.Ctx, boardID uuid.UUID, perm boardPermission) (*models.Board, string, error) {
+	return checkBoardPermission(boardID, middleware.GetUserID(c), perm)
+}
+
+// checkBoardPermission is authorizeBoard for callers that already have the user ID
+func checkBoardPermission(boardID, userID uuid.UUID, perm boardPermission) (*models.Board, string, error) {
+	var board models.Board
+	if err := database.DB.First(&board, boardID).Error; err != nil {
+		return nil, "", fiber.NewError(fiber.StatusNotFound, "Board not found")
+	}
+
+	role := boardRole(&board, userID)
+	if role == "" {
+		return nil, "", fiber.NewError(fiber.StatusNotFound, "Board not found")
+	}
+	if !roleAllows(role, perm) {
+		return nil, role, fiber.NewError(fiber.StatusForbidden, "Your role on this board doesn't allow this")
+	}
+
+	return &board, role, nil
+}
+
+// canManageRole reports whether someone with actorRole may change or remove a
+// member holding targetRole: only members ranked strictly below the actor
+func canManageRole(actorRole, targetRole string) bool {
+	return roleAllows(actorRole, permManageMembers) &&
+		models.RoleRank(targetRole) < models.RoleRank(actorRole)
+}
+
+// canGrantRole reports whether actorRole may hand out role via invite or role change.
+// Admins can grant editor and viewer; only the owner can make admins.
+func canGrantRole(actorRole, role string) bool {
+	return models.IsAssignableRole(role) &&
+		models.RoleRank(role) < models.RoleRank(actorRole)
+}
@@ -57,14 +57,14 @@ func GetBoards(c *fiber.Ctx) error {
 			database.DB.Model(&models.GoalMember{}).
 				Where("goal_id IN ? AND user_id = ? AND is_completed = true", goalIDs, userID).
 				Find(&goalMembers)
-			completedCount = len(goalMembers)
-			// Map completed goalIDs back to positions
+			// Map completed goalIDs back to positions; the grace square is complete for everyone
 			completedGoalIDs := make(map[uuid.UUID]bool, len(goalMembers))
 			for _, gm := range goalMembers {
 				completedGoalIDs[gm.GoalID] = true
 			}
 			for _, g := range board.Goals {
-				if completedGoalIDs[g.ID] {
+				if completedGoalIDs[g.ID] || g.IsGraceSquare {
+					completedCount++
 					completedPositions = append(completedPositions, g.Position)
 				}
 			}
@@ -83,7 +83,14 @@ func GetBoards(c *fiber.Ctx) error {
 
 		// Build member info
 		var members []models.MemberInfo
+		myRole := ""
+		if board.UserID == userID {
+			myRole = models.RoleOwner
+		}
 		for _, m := range board.Members {
+			if m.UserID == userID && myRole == "" {
+				myRole = m.Role
+			}
 			members = append(members, models.MemberInfo{
 				ID:          m.UserID,
 				Name:        m.User.Name,
@@ -94,19 +101,21 @@ func GetBoards(c *fiber.Ctx) error {
 		}
 
 		summaries[i] = models.BoardSummary{
-			ID:                 board.ID,
-			Title:              board.Title,
-			Year:               board.Year,
-			GridSize:           board.GridSize,
-			Category:           board.Category,
-			BoardType:          board.BoardType,
-			MaxMembers:         board.MaxMembers,
-			IsDefault:          board.IsDefault,
-			GoalCount:          goalCount,
-			CompletedCount:     completedCount,
-			CompletedPositions: completedPositions,
-			MemberCount:        len(board.Members),
-			Members:            members,
+			ID:                  board.ID,
+			Title:               board.Title,
+			Year:                board.Year,
+			GridSize:            board.GridSize,
+			Category:            board.Category,
+			BoardType:           board.BoardType,
+			MaxMembers:          board.MaxMembers,
+			IsDefault:           board.IsDefault,
+			GraceSquarePosition: board.GraceSquarePosition,
+			GoalCount:           goalCount,
+			CompletedCount:      completedCount,
+			CompletedPositions:  completedPositions,
+			MemberCount:         len(board.Members),
+			Members:             members,
+			MyRole:              myRole,
 		}
 	}
 
@@ -122,6 +131,11 @@ func GetBoard(c *fiber.Ctx) error {
 		})
 	}
 
+	_, role, err := authorizeBoard(c, boardID, permViewBoard)
+	if err != nil {
+		return err
+	}
+
 	var board models.Board
 	if err := database.DB.
 		Where("id = ?", boardID).
@@ -134,24 +148,20 @@ func GetBoard(c *fiber.Ctx) error {
 			return db.Order("created_at ASC")
 		}).
 		Preload("Members.User").
+		Preload("Milestones", func(db *gorm.DB) *gorm.DB {
+			return db.Where("revoked_at IS NULL").Order("reached_at ASC")
+		}).
 		First(&board).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
 			"error": "Board not found",
 		})
 	}
 
-	// Check access: user must be owner or a member
-	if board.UserID != userID {
-		var membership models.BoardMember
-		if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).First(&membership).Error; err != nil {
-			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-				"error": "Board not found",
-			})
-		}
-	}
+	board.MyRole = role
 
 	// Overlay per-member status for shared boards
 	overlayMemberStatus(board.Goals, board.BoardType, userID)
+	attachStreaks(board.Goals, &board, userID)
 
 	return c.JSON(board)
 }
@@ -167,24 +177,76 @@ func CreateBoard(c *fiber.Ctx) error {
 		})
 	}
 
+	var template *models.BoardTemplate
+	if req.TemplateID != nil {
+		t, err := findVisibleTemplate(*req.TemplateID, userID)
+		if err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Template not found",
+			})
+		}
+		template = t
+		if req.Title == "" {
+			req.Title = t.Title
+		}
+	}
+
 	if req.Title == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Title is required",
 		})
 	}
 
+	var board *models.Board
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		board, err = createBoard(tx, userID, req, template)
+		return err
+	})
+	if _, ok := err.(*fiber.Error); ok {
+		return err
+	}
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create board",
+		})
+	}
+
+	if template != nil {
+		database.DB.Model(template).UpdateColumn("use_count", gorm.Expr("use_count + 1"))
+	}
+
+	database.DB.Preload("Goals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Goals.MiniGoals").Preload("Members.User").First(board, board.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(board)
+}
+
+// createBoard applies the board defaults, stores the board with its owner
+// membership and grace square and, when a template is given, fills in its
+// goals. Run inside a transaction.
+func createBoard(tx *gorm.DB, userID uuid.UUID, req models.CreateBoardRequest, template *models.BoardTemplate) (*models.Board, error) {
 	year := req.Year
 	if year == 0 {
 		year = time.Now().Year()
 	}
 
 	gridSize := req.GridSize
+	if template != nil {
+		gridSize = template.GridSize
+	}
 	if gridSize != 3 && gridSize != 7 {
 		gridSize = 5 // Default to 5x5
 	}
 
+	category := req.Category
+	if category == nil && template != nil {
+		category = template.Category
+	}
+
 	var count int64
-	database.DB.Model(&models.Board{}).Where("user_id = ?", userID).Count(&count)
+	tx.Model(&models.Board{}).Where("user_id = ?", userID).Count(&count)
 
 	boardType := req.BoardType
 	if boardType != "shared" {
@@ -196,37 +258,56 @@ func CreateBoard(c *fiber.Ctx) error {
 		maxMembers = 5
 	}
 
+	gracePosition, err := graceSquarePosition(req, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
+	patterns, customPatterns, err := validateMilestonePatterns(req.MilestonePatterns, req.CustomPatterns, gridSize)
+	if err != nil {
+		return nil, err
+	}
+
 	board := models.Board{
-		UserID:           userID,
-		Title:            req.Title,
-		Year:             year,
-		GridSize:         gridSize,
-		Category:         req.Category,
-		BoardType:        boardType,
-		MaxMembers:       maxMembers,
-		GraceSquareTitle: req.GraceSquareTitle,
-		IsDefault:        count == 0,
-	}
-
-	if err := database.DB.Create(&board).Error; err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "Failed to create board",
-		})
+		UserID:              userID,
+		Title:               req.Title,
+		Year:                year,
+		GridSize:            gridSize,
+		Category:            category,
+		BoardType:           boardType,
+		MaxMembers:          maxMembers,
+		GraceSquareTitle:    req.GraceSquareTitle,
+		GraceSquarePosition: gracePosition,
+		MilestonePatterns:   patterns,
+		CustomPatterns:      customPatterns,
+		IsDefault:           count == 0,
+	}
+
+	if err := tx.Create(&board).Error; err != nil {
+		return nil, err
 	}
 
 	// Auto-create board member with owner role
 	member := models.BoardMember{
 		BoardID: board.ID,
 		UserID:  userID,
-		Role:    "owner",
+		Role:    models.RoleOwner,
+	}
+	if err := tx.Create(&member).Error; err != nil {
+		return nil, err
 	}
-	database.DB.Create(&member)
 
-	database.DB.Preload("Goals", func(db *gorm.DB) *gorm.DB {
-		return db.Order("position ASC")
-	}).Preload("Members.User").First(&board, board.ID)
+	if err := createGraceSquare(tx, &board); err != nil {
+		return nil, err
+	}
 
-	return c.Status(fiber.StatusCreated).JSON(board)
+	if template != nil {
+		if err := applyTemplate(tx, &board, template); err != nil {
+			return nil, err
+		}
+	}
+
+	return &board, nil
 }
 
 func UpdateBoard(c *fiber.Ctx) error {
@@ -238,11 +319,9 @@ func UpdateBoard(c *fiber.Ctx) error {
 		})
 	}
 
-	var board models.Board
-	if err := database.DB.Where("id = ? AND user_id = ?", boardID, userID).First(&board).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	board, _, err := authorizeBoard(c, boardID, permEditBoard)
+	if err != nil {
+		return err
 	}
 
 	var req models.UpdateBoardRequest
@@ -252,10 +331,34 @@ func UpdateBoard(c *fiber.Ctx) error {
 		})
 	}
 
+	// The default board is the owner's personal preference
+	if req.IsDefault != nil && board.UserID != userID {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "Only the board owner can change the default board",
+		})
+	}
+
 	if req.Title != nil {
 		board.Title = *req.Title
 	}
 
+	// Milestones are brought in line with the new patterns once it's saved
+	patternsChanged := req.MilestonePatterns != nil || req.CustomPatterns != nil
+	if patternsChanged {
+		keys, custom := board.MilestonePatterns, board.CustomPatterns
+		if req.MilestonePatterns != nil {
+			keys = *req.MilestonePatterns
+		}
+		if req.CustomPatterns != nil {
+			custom = *req.CustomPatterns
+		}
+		keys, custom, err = validateMilestonePatterns(keys, custom, board.GridSize)
+		if err != nil {
+			return err
+		}
+		board.MilestonePatterns, board.CustomPatterns = keys, custom
+	}
+
 	if req.IsDefault != nil && *req.IsDefault {
 		// Unset other boards as default
 		database.DB.Model(&models.Board{}).
@@ -264,11 +367,14 @@ func UpdateBoard(c *fiber.Ctx) error {
 		board.IsDefault = true
 	}
 
-	if err := database.DB.Save(&board).Error; err != nil {
+	if err := database.DB.Save(board).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to update board",
 		})
 	}
+	if patternsChanged {
+		syncBoardMilestones(board, nil)
+	}
 
 	return c.JSON(board)
 }
@@ -285,22 +391,31 @@ func DeleteBoard(c *fiber.Ctx) error {
 	var count int64
 	database.DB.Model(&models.Board{}).Where("user_id = ?", userID).Count(&count)
 
-	var board models.Board
-	if err := database.DB.Where("id = ? AND user_id = ?", boardID, userID).First(&board).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	board, _, err := authorizeBoard(c, boardID, permDeleteBoard)
+	if err != nil {
+		return err
 	}
 
 	wasDefault := board.IsDefault
 
-	database.DB.Where("board_id = ?", boardID).Delete(&models.Goal{})
+	var goalIDs []uuid.UUID
+	database.DB.Model(&models.Goal{}).Where("board_id = ?", boardID).Pluck("id", &goalIDs)
+	completions := goalCompletions(board, goalIDs)
 
-	if err := database.DB.Delete(&board).Error; err != nil {
+	// Goals go to the trash with the board under one timestamp so RestoreBoard brings them back together
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		at := trashTimestamp()
+		if err := softDeleteGoals(tx, goalIDs, at); err != nil {
+			return err
+		}
+		return tx.Model(board).Update("deleted_at", at).Error
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete board",
 		})
 	}
+	undoTrashedCompletions(board, completions)
 
 	if wasDefault {
 		var newDefault models.Board
@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"math"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// errCheckInGoalToggle is returned when something tries to complete a numeric
+// goal directly instead of through check-ins
+var errCheckInGoalToggle = fiber.NewError(fiber.StatusBadRequest, "Progress on this goal comes from check-ins")
+
+// tracksCheckIns reports whether a goal's progress comes from check-ins:
+// numeric goals and habits
+func tracksCheckIns(goal *models.Goal) bool {
+	return goal.TargetValue != nil || goal.Recurrence != nil
+}
+
+// GetCheckIns lists a goal's check-ins, newest first. On shared boards this
+// includes every member's check-ins, each with its userId.
+func GetCheckIns(c *fiber.Ctx) error {
+	goal, _, err := findGoalByBoardAndPosition(c, permViewBoard)
+	if err != nil {
+		return err
+	}
+
+	var checkIns []models.GoalCheckIn
+	database.DB.Where("goal_id = ?", goal.ID).Order("checked_in_at DESC").Find(&checkIns)
+
+	return c.JSON(checkIns)
+}
+
+// CreateCheckIn logs an increment towards a goal's target. Reaching the target
+// completes the goal for the caller, with the same gems, milestones and
+// notifications as toggling it.
+func CreateCheckIn(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	goal, board, err := findGoalByBoardAndPosition(c, permTrackProgress)
+	if err != nil {
+		return err
+	}
+	if !tracksCheckIns(goal) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "This goal has no target; set targetValue or recurrence first",
+		})
+	}
+
+	var req models.CreateCheckInRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if goal.Recurrence != nil {
+		// A habit check-in just marks the day
+		if req.Value < 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Habit check-ins can't be negative; delete the check-in instead",
+			})
+		}
+		req.Value = 1
+	}
+	if req.Value == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Value is required",
+		})
+	}
+
+	checkIn := models.GoalCheckIn{
+		GoalID:      goal.ID,
+		UserID:      userID,
+		Value:       req.Value,
+		Note:        req.Note,
+		CheckedInAt: time.Now().UTC(),
+	}
+	if req.CheckedInAt != nil {
+		if req.CheckedInAt.After(time.Now().Add(time.Minute)) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "checkedInAt can't be in the future",
+			})
+		}
+		checkIn.CheckedInAt = req.CheckedInAt.UTC()
+	}
+
+	if err := database.DB.Create(&checkIn).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to log check-in",
+		})
+	}
+
+	completed := recalculateCheckInProgress(board, goal, userID)
+
+	gemsAwarded := 0
+	milestones := []string{}
+	if completed {
+		gemsAwarded, milestones = goalCompleted(*board, *goal, userID)
+	}
+	broadcastCheckIn(board, goal, userID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"checkIn":     checkIn,
+		"goal":        goal,
+		"gemsAwarded": gemsAwarded,
+		"milestones":  milestones,
+	})
+}
+
+// DeleteCheckIn removes one of the caller's check-ins and recalculates progress.
+// Dropping back below the target un-completes the goal.
+func DeleteCheckIn(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	goal, board, err := findGoalByBoardAndPosition(c, permTrackProgress)
+	if err != nil {
+		return err
+	}
+
+	checkInID, err := uuid.Parse(c.Params("checkInId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid check-in ID",
+		})
+	}
+
+	var checkIn models.GoalCheckIn
+	if err := database.DB.Where("id = ? AND goal_id = ? AND user_id = ?", checkInID, goal.ID, userID).First(&checkIn).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Check-in not found",
+		})
+	}
+
+	if err := database.DB.Delete(&checkIn).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete check-in",
+		})
+	}
+
+	if tracksCheckIns(goal) {
+		recalculateCheckInProgress(board, goal, userID)
+		broadcastCheckIn(board, goal, userID)
+	}
+
+	return c.JSON(fiber.Map{
+		"goal": goal,
+	})
+}
+
+// checkInTotal sums the check-ins counting towards a goal: everyone's on a
+// personal board, only the given user's on a shared one
+func checkInTotal(goalID uuid.UUID, userID *uuid.UUID) float64 {
+	query := database.DB.Model(&models.GoalCheckIn{}).Where("goal_id = ?", goalID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	var total float64
+	query.Select("COALESCE(SUM(value), 0)").Scan(&total)
+	return total
+}
+
+// checkInProgress turns a check-in total into a progress percentage and
+// status. Only reaching the target counts as 100%.
+func checkInProgress(total, target float64) (int, string) {
+	if total >= target {
+		return 100, "completed"
+	}
+	if total <= 0 {
+		return 0, "not_started"
+	}
+	progress := int(math.Round(total / target * 100))
+	if progress >= 100 {
+		progress = 99
+	}
+	return progress, "in_progress"
+}
+
+// recalculateCheckInProgress re-derives the user's progress on a numeric or
+// habit goal from its check-ins: the goal row on personal boards, the user's
+// GoalMember row on shared ones. goal is updated in place with the user's view.
+// Reports whether the goal just became complete.
+func recalculateCheckInProgress(board *models.Board, goal *models.Goal, userID uuid.UUID) bool {
+	if !tracksCheckIns(goal) {
+		return false
+	}
+	now := time.Now()
+
+	// Personal boards count everyone's check-ins in the owner's calendar
+	subjectID, filter := userID, &userID
+	if board.BoardType != "shared" {
+		subjectID, filter = board.UserID, nil
+	}
+
+	var total float64
+	var progress int
+	var status string
+	if goal.Recurrence != nil {
+		total, progress, status = habitProgress(goal, subjectID, filter)
+	} else {
+		total = checkInTotal(goal.ID, filter)
+		progress, status = checkInProgress(total, *goal.TargetValue)
+	}
+
+	if board.BoardType != "shared" {
+		wasCompleted := goal.IsCompleted
+
+		goal.CurrentValue = total
+		goal.Progress = progress
+		goal.Status = status
+		goal.IsCompleted = status == "completed"
+		if !goal.IsCompleted {
+			goal.CompletedAt = nil
+		} else if !wasCompleted {
+			goal.CompletedAt = &now
+		}
+		database.DB.Model(&models.Goal{}).Where("id = ?", goal.ID).Updates(map[string]interface{}{
+			"current_value": goal.CurrentValue,
+			"progress":      goal.Progress,
+			"status":        goal.Status,
+			"is_completed":  goal.IsCompleted,
+			"completed_at":  goal.CompletedAt,
+		})
+		goal.CompletedBy = syncCompletedBy(goal.ID, &userID)
+		if wasCompleted && !goal.IsCompleted {
+			completionUndone(board, goal.ID, userID)
+		}
+		return goal.IsCompleted && !wasCompleted
+	}
+
+	var gm models.GoalMember
+	if err := database.DB.Where("goal_id = ? AND user_id = ?", goal.ID, userID).First(&gm).Error; err != nil {
+		gm = models.GoalMember{GoalID: goal.ID, UserID: userID}
+	}
+	wasCompleted := gm.IsCompleted
+
+	gm.CurrentValue = total
+	gm.Progress, gm.Status = progress, status
+	gm.IsCompleted = gm.Status == "completed"
+	if !gm.IsCompleted {
+		gm.CompletedAt = nil
+	} else if !wasCompleted {
+		gm.CompletedAt = &now
+	}
+
+	if gm.ID == (uuid.UUID{}) {
+		database.DB.Create(&gm)
+	} else {
+		database.DB.Save(&gm)
+	}
+	goal.CompletedBy = syncCompletedBy(goal.ID, &userID)
+	if wasCompleted && !gm.IsCompleted {
+		completionUndone(board, goal.ID, userID)
+	}
+
+	goal.CurrentValue = gm.CurrentValue
+	goal.Progress = gm.Progress
+	goal.Status = gm.Status
+	goal.IsCompleted = gm.IsCompleted
+	goal.CompletedAt = gm.CompletedAt
+	return gm.IsCompleted && !wasCompleted
+}
+
+// recalculateTrackingChange re-derives progress after a goal gains, changes
+// or loses its target or habit rule: from check-ins for everyone who has
+// logged any, or from mini-goals again once both are gone. Anyone the change
+// carries over their target is paid out as if they'd just checked in.
+func recalculateTrackingChange(board *models.Board, goalID uuid.UUID) {
+	var goal models.Goal
+	if err := database.DB.First(&goal, goalID).Error; err != nil {
+		return
+	}
+	if !tracksCheckIns(&goal) {
+		database.DB.Model(&goal).Update("current_value", 0)
+		database.DB.Model(&models.GoalMember{}).Where("goal_id = ?", goalID).Update("current_value", 0)
+		refreshGoalProgress(goalID, board.BoardType)
+		return
+	}
+
+	if board.BoardType != "shared" {
+		if recalculateCheckInProgress(board, &goal, board.UserID) {
+			goalCompleted(*board, goal, board.UserID)
+		}
+		return
+	}
+
+	var userIDs []uuid.UUID
+	database.DB.Model(&models.GoalMember{}).Where("goal_id = ?", goalID).Pluck("user_id", &userIDs)
+	var checkedIn []uuid.UUID
+	database.DB.Model(&models.GoalCheckIn{}).Where("goal_id = ?", goalID).Distinct().Pluck("user_id", &checkedIn)
+	seen := map[uuid.UUID]bool{}
+	for _, id := range append(userIDs, checkedIn...) {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		g := goal
+		if recalculateCheckInProgress(board, &g, id) {
+			goalCompleted(*board, g, id)
+		}
+	}
+}
+
+// broadcastCheckIn tells other members of a shared board that a goal's progress moved
+func broadcastCheckIn(board *models.Board, goal *models.Goal, userID uuid.UUID) {
+	if board.BoardType != "shared" {
+		return
+	}
+	WS.Broadcast(board.ID, userID, WSEvent{
+		Type:    EventGoalUpdated,
+		BoardID: board.ID.String(),
+		UserID:  userID.String(),
+		Data:    goal,
+	})
+}
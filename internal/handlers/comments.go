@@ -39,10 +39,8 @@ func AddComment(c *fiber.Ctx) error {
 		})
 	}
 
-	if !isBoardMember(goal.BoardID, userID) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have access to this board",
-		})
+	if _, _, err := checkBoardPermission(goal.BoardID, userID, permInteract); err != nil {
+		return err
 	}
 
 	comment := models.Comment{
@@ -61,6 +59,7 @@ func AddComment(c *fiber.Ctx) error {
 
 	// Log activity
 	LogActivity(goal.BoardID, userID, "comment_added", &goalID, nil)
+	evaluateAchievements(userID, metricComments)
 
 	// Notify goal owner if different from commenter
 	if goal.CompletedBy != nil && *goal.CompletedBy != userID {
@@ -112,10 +111,8 @@ func GetGoalComments(c *fiber.Ctx) error {
 		})
 	}
 
-	if !isBoardMember(goal.BoardID, userID) {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You don't have access to this board",
-		})
+	if _, _, err := checkBoardPermission(goal.BoardID, userID, permViewBoard); err != nil {
+		return err
 	}
 
 	var comments []models.Comment
@@ -127,7 +124,7 @@ func GetGoalComments(c *fiber.Ctx) error {
 	return c.JSON(comments)
 }
 
-// DeleteComment deletes a comment (only by the comment author)
+// DeleteComment deletes a comment (by its author or a board admin)
 func DeleteComment(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	commentID, err := uuid.Parse(c.Params("commentId"))
@@ -144,15 +141,18 @@ func DeleteComment(c *fiber.Ctx) error {
 		})
 	}
 
-	if comment.UserID != userID {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "You can only delete your own comments",
-		})
-	}
-
 	var goal models.Goal
 	database.DB.First(&goal, comment.GoalID)
 
+	// Authors can delete their own comments; admins can moderate any
+	if comment.UserID != userID {
+		if _, _, err := checkBoardPermission(goal.BoardID, userID, permModerate); err != nil {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": "You can only delete your own comments",
+			})
+		}
+	}
+
 	database.DB.Delete(&comment)
 
 	// Broadcast via WebSocket
@@ -0,0 +1,282 @@
+package handlers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// errAlreadyAwarded means an award's idempotency key already has a live transaction
+var errAlreadyAwarded = errors.New("already awarded")
+
+// gemAward is a ledger entry waiting to be posted
+type gemAward struct {
+	Amount    int
+	Reason    string
+	BoardID   uuid.UUID
+	GoalID    *uuid.UUID
+	Milestone *string
+	Key       string
+}
+
+// goalAwardKey and milestoneAwardKey identify awards so each pays out once
+func goalAwardKey(goalID uuid.UUID) string {
+	return "goal:" + goalID.String()
+}
+
+func milestoneAwardKey(boardID uuid.UUID, line string) string {
+	return "milestone:" + boardID.String() + ":" + line
+}
+
+// GetGems returns the current user's gem balance and paginated ledger, newest first
+func GetGems(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	page, _ := strconv.Atoi(c.Query("page", "1"))
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 50 {
+		limit = 20
+	}
+	offset := (page - 1) * limit
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var transactions []models.GemTransaction
+	database.DB.Where("user_id = ?", userID).
+		Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&transactions)
+
+	var total int64
+	database.DB.Model(&models.GemTransaction{}).Where("user_id = ?", userID).Count(&total)
+
+	return c.JSON(fiber.Map{
+		"totalGems":    user.TotalGems,
+		"transactions": transactions,
+		"total":        total,
+		"page":         page,
+		"limit":        limit,
+	})
+}
+
+// postGemAward records an award and adds it to the user's balance. Returns
+// false when the same award is already on the ledger.
+func postGemAward(userID uuid.UUID, award gemAward) bool {
+	boardID := award.BoardID
+	key := award.Key
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing int64
+		tx.Model(&models.GemTransaction{}).
+			Where("user_id = ? AND idempotency_key = ? AND reversed_at IS NULL", userID, key).
+			Count(&existing)
+		if existing > 0 {
+			return errAlreadyAwarded
+		}
+
+		entry := models.GemTransaction{
+			UserID:         userID,
+			Amount:         award.Amount,
+			Reason:         award.Reason,
+			BoardID:        &boardID,
+			GoalID:         award.GoalID,
+			Milestone:      award.Milestone,
+			IdempotencyKey: &key,
+		}
+		if err := tx.Create(&entry).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("total_gems", gorm.Expr("total_gems + ?", award.Amount)).Error
+	})
+	return err == nil
+}
+
+// reverseGemAwards cancels live awards matching the query: each is marked
+// reversed and a negative entry is posted against the same user
+func reverseGemAwards(query string, args ...interface{}) {
+	var awards []models.GemTransaction
+	database.DB.Where("reversed_at IS NULL AND reason <> ? AND amount > 0", models.GemReasonReversal).
+		Where(query, args...).
+		Find(&awards)
+
+	now := time.Now()
+	for _, award := range awards {
+		award := award
+		database.DB.Transaction(func(tx *gorm.DB) error {
+			// Claim the award first so concurrent reversals can't both post
+			result := tx.Model(&models.GemTransaction{}).
+				Where("id = ? AND reversed_at IS NULL", award.ID).
+				Update("reversed_at", now)
+			if result.Error != nil || result.RowsAffected == 0 {
+				return result.Error
+			}
+
+			entry := models.GemTransaction{
+				UserID:     award.UserID,
+				Amount:     -award.Amount,
+				Reason:     models.GemReasonReversal,
+				BoardID:    award.BoardID,
+				GoalID:     award.GoalID,
+				Milestone:  award.Milestone,
+				ReversalOf: &award.ID,
+			}
+			if err := tx.Create(&entry).Error; err != nil {
+				return err
+			}
+			return tx.Model(&models.User{}).Where("id = ?", award.UserID).
+				Update("total_gems", gorm.Expr("total_gems - ?", award.Amount)).Error
+		})
+	}
+}
+
+// completionUndone takes back the gems for a goal someone no longer has
+// completed, and revokes and takes back any milestones on the board that are
+// no longer complete. On personal boards the board's completion is what counts, so
+// every award on the board is checked; on shared boards only userID's.
+func completionUndone(board *models.Board, goalID, userID uuid.UUID) {
+	if board.BoardType != "shared" {
+		reverseGemAwards("goal_id = ? AND reason = ?", goalID, models.GemReasonGoalCompleted)
+		lines := completedLines(board, completedSquares(board, board.UserID))
+		revokeStaleMilestones(board.ID, nil, lines)
+		reverseStaleMilestones(board.ID, nil, lines)
+		return
+	}
+
+	reverseGemAwards("goal_id = ? AND user_id = ? AND reason = ?", goalID, userID, models.GemReasonGoalCompleted)
+	lines := completedLines(board, completedSquares(board, userID))
+	revokeStaleMilestones(board.ID, &userID, lines)
+	reverseStaleMilestones(board.ID, &userID, lines)
+}
+
+// reverseStaleMilestones reverses milestone awards on a board whose line is no
+// longer among the completed lines
+func reverseStaleMilestones(boardID uuid.UUID, userID *uuid.UUID, lines []string) {
+	query := "board_id = ? AND reason = ?"
+	args := []interface{}{boardID, models.GemReasonMilestone}
+	if userID != nil {
+		query += " AND user_id = ?"
+		args = append(args, *userID)
+	}
+	if len(lines) > 0 {
+		query += " AND milestone NOT IN ?"
+		args = append(args, lines)
+	}
+	reverseGemAwards(query, args...)
+}
+
+// undoGoalCompletion is completionUndone for callers that only have the goal.
+// userID is the member whose completion was lost; nil means the board owner.
+func undoGoalCompletion(goalID uuid.UUID, userID *uuid.UUID) {
+	var goal models.Goal
+	if err := database.DB.Select("id", "board_id").First(&goal, goalID).Error; err != nil {
+		return
+	}
+	var board models.Board
+	if err := database.DB.First(&board, goal.BoardID).Error; err != nil {
+		return
+	}
+	if userID == nil {
+		userID = &board.UserID
+	}
+	completionUndone(&board, goalID, *userID)
+}
+
+// payGoalCompletion is goalCompleted for callers that only have the goal.
+// userID is the member who just completed it; nil means the board owner.
+func payGoalCompletion(goalID uuid.UUID, userID *uuid.UUID) {
+	var goal models.Goal
+	if err := database.DB.First(&goal, goalID).Error; err != nil {
+		return
+	}
+	var board models.Board
+	if err := database.DB.First(&board, goal.BoardID).Error; err != nil {
+		return
+	}
+	if userID == nil {
+		// Shared boards complete per member, never for the owner alone
+		if board.BoardType == "shared" {
+			return
+		}
+		userID = &board.UserID
+	}
+	goalCompleted(board, goal, *userID)
+}
+
+// goalCompletions maps each of goalIDs that someone has completed to who
+// completed it: the board owner on personal boards, members with a completed
+// GoalMember row on shared ones. Read it before the goals go to the trash.
+func goalCompletions(board *models.Board, goalIDs []uuid.UUID) map[uuid.UUID][]uuid.UUID {
+	completions := map[uuid.UUID][]uuid.UUID{}
+	if len(goalIDs) == 0 {
+		return completions
+	}
+
+	if board.BoardType != "shared" {
+		var completed []uuid.UUID
+		database.DB.Model(&models.Goal{}).
+			Where("id IN ? AND status = ?", goalIDs, "completed").
+			Pluck("id", &completed)
+		for _, id := range completed {
+			completions[id] = []uuid.UUID{board.UserID}
+		}
+		return completions
+	}
+
+	var members []models.GoalMember
+	database.DB.Where("goal_id IN ? AND is_completed = true", goalIDs).Find(&members)
+	for _, gm := range members {
+		completions[gm.GoalID] = append(completions[gm.GoalID], gm.UserID)
+	}
+	return completions
+}
+
+// undoTrashedCompletions takes back the completion gems of everyone who had
+// one of the goals completed when it was cleared, resized away or trashed,
+// then brings every member's milestones in line with what's left
+func undoTrashedCompletions(board *models.Board, completions map[uuid.UUID][]uuid.UUID) {
+	for goalID, userIDs := range completions {
+		if board.BoardType != "shared" {
+			reverseGemAwards("goal_id = ? AND reason = ?", goalID, models.GemReasonGoalCompleted)
+			continue
+		}
+		for _, userID := range userIDs {
+			reverseGemAwards("goal_id = ? AND user_id = ? AND reason = ?", goalID, userID, models.GemReasonGoalCompleted)
+		}
+	}
+	syncBoardMilestones(board, nil)
+}
+
+// redoRestoredCompletions pays the completion gems back to everyone who has
+// one of the goals completed after it comes out of the trash, then brings
+// every member's milestones in line with the restored squares
+func redoRestoredCompletions(board *models.Board, goalIDs []uuid.UUID) {
+	for goalID, userIDs := range goalCompletions(board, goalIDs) {
+		goalID := goalID
+		for _, userID := range userIDs {
+			postGemAward(userID, gemAward{
+				Amount:  models.CompletionGems[board.GridSize],
+				Reason:  models.GemReasonGoalCompleted,
+				BoardID: board.ID,
+				GoalID:  &goalID,
+				Key:     goalAwardKey(goalID),
+			})
+		}
+	}
+	syncBoardMilestones(board, nil)
+}
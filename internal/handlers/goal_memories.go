@@ -8,7 +8,7 @@ import (
 )
 
 func CreateGoalMemory(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -47,7 +47,7 @@ func CreateGoalMemory(c *fiber.Ctx) error {
 }
 
 func ListGoalMemories(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permViewBoard)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -59,7 +59,7 @@ func ListGoalMemories(c *fiber.Ctx) error {
 }
 
 func UpdateGoalMemory(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -107,7 +107,7 @@ func UpdateGoalMemory(c *fiber.Ctx) error {
 }
 
 func DeleteGoalMemory(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/arnold/bingoals-api/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 func UpdateGoal(c *fiber.Ctx) error {
@@ -26,18 +27,9 @@ func UpdateGoal(c *fiber.Ctx) error {
 			"error": "Invalid position",
 		})
 	}
-	var board models.Board
-	if err := database.DB.First(&board, boardID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
-	}
-
-	// Check access: owner or member
-	if board.UserID != userID && !isBoardMember(boardID, userID) {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	board, _, err := authorizeBoard(c, boardID, permEditGoals)
+	if err != nil {
+		return err
 	}
 
 	maxPosition := board.GridSize*board.GridSize - 1
@@ -65,6 +57,15 @@ func UpdateGoal(c *fiber.Ctx) error {
 		})
 	}
 
+	clearing := req.Title != nil && *req.Title == ""
+
+	if goal.IsGraceSquare {
+		// The grace square can be renamed and decorated but never cleared or un-completed
+		if (req.Title != nil && *req.Title == "") || req.IsCompleted != nil || req.TargetValue != nil || req.Recurrence != nil {
+			return errGraceSquareLocked
+		}
+	}
+
 	if req.Title != nil {
 		goal.Title = req.Title
 	}
@@ -80,6 +81,51 @@ func UpdateGoal(c *fiber.Ctx) error {
 	if req.Mood != nil {
 		goal.Mood = req.Mood
 	}
+	if req.DueDate != nil {
+		dueDate, err := parseDueDate(*req.DueDate)
+		if err != nil {
+			return err
+		}
+		goal.DueDate = dueDate
+	}
+	if req.TargetValue != nil {
+		switch {
+		case *req.TargetValue < 0:
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "targetValue can't be negative",
+			})
+		case *req.TargetValue == 0:
+			goal.TargetValue = nil
+		default:
+			goal.TargetValue = req.TargetValue
+		}
+	}
+	if req.Unit != nil {
+		goal.Unit = req.Unit
+		if *req.Unit == "" {
+			goal.Unit = nil
+		}
+	}
+	if err := applyRecurrence(&goal, req); err != nil {
+		return err
+	}
+	if goal.TargetValue != nil && goal.Recurrence != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "A goal can have a target or a recurrence, not both",
+		})
+	}
+	if req.IsCompleted != nil && tracksCheckIns(&goal) {
+		return errCheckInGoalToggle
+	}
+	previousAssignee := goal.AssignedTo
+	if req.AssignedTo != nil {
+		assignee, err := parseAssignee(board, *req.AssignedTo)
+		if err != nil {
+			return err
+		}
+		goal.AssignedTo = assignee
+	}
+	wasCompleted := goal.Status == "completed"
 	if req.IsCompleted != nil {
 		goal.IsCompleted = *req.IsCompleted
 		if *req.IsCompleted {
@@ -92,28 +138,29 @@ func UpdateGoal(c *fiber.Ctx) error {
 		}
 	}
 
-	// Clear goal: when title is set to empty string, reset everything
-	if req.Title != nil && *req.Title == "" {
-		goal.Description = nil
-		goal.Icon = nil
-		goal.ImageURL = nil
-		goal.Mood = nil
-		goal.Status = "not_started"
-		goal.IsCompleted = false
-		goal.Progress = 0
-		goal.CompletedAt = nil
-		// Delete associated mini-goals, reflection, and per-member state
+	// Clear goal: when title is set to empty string, the old goal goes to the
+	// trash with its mini-goals, reflection, memories and per-member state, and
+	// the square starts over as a fresh blank row
+	if clearing {
 		if !isNew {
-			// Delete MiniGoalMember rows for this goal's mini-goals
-			var miniGoalIDs []uuid.UUID
-			database.DB.Model(&models.MiniGoal{}).Where("goal_id = ?", goal.ID).Pluck("id", &miniGoalIDs)
-			if len(miniGoalIDs) > 0 {
-				database.DB.Where("mini_goal_id IN ?", miniGoalIDs).Delete(&models.MiniGoalMember{})
+			completions := goalCompletions(board, []uuid.UUID{goal.ID})
+			err := database.DB.Transaction(func(tx *gorm.DB) error {
+				return softDeleteGoals(tx, []uuid.UUID{goal.ID}, trashTimestamp())
+			})
+			if err != nil {
+				return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+					"error": "Failed to clear goal",
+				})
 			}
-			database.DB.Where("goal_id = ?", goal.ID).Delete(&models.MiniGoal{})
-			database.DB.Where("goal_id = ?", goal.ID).Delete(&models.Reflection{})
-			database.DB.Where("goal_id = ?", goal.ID).Delete(&models.GoalMember{})
+			undoTrashedCompletions(board, completions)
 		}
+		goal = models.Goal{
+			BoardID:  boardID,
+			Position: position,
+			Title:    req.Title,
+		}
+		isNew = true
+		previousAssignee = nil
 	}
 
 	if isNew {
@@ -130,6 +177,29 @@ func UpdateGoal(c *fiber.Ctx) error {
 		}
 	}
 
+	if req.DueDate != nil && !isNew {
+		rescheduleReminders("goal_id = ? AND mini_goal_id IS NULL", goal.ID)
+	}
+	trackingChanged := req.TargetValue != nil || req.Recurrence != nil || req.TimesPerPeriod != nil || req.RequiredPeriods != nil
+	if trackingChanged && !clearing {
+		recalculateTrackingChange(board, goal.ID)
+		database.DB.First(&goal, goal.ID)
+	}
+	if req.IsCompleted != nil && !clearing {
+		goal.CompletedBy = syncCompletedBy(goal.ID, &userID)
+		if board.BoardType != "shared" {
+			// Same payouts and take-backs as ToggleGoalCompletion
+			if !wasCompleted && goal.IsCompleted {
+				goalCompleted(*board, goal, userID)
+			} else if wasCompleted && !goal.IsCompleted {
+				completionUndone(board, goal.ID, userID)
+			}
+		}
+	}
+	if !sameUserID(previousAssignee, goal.AssignedTo) {
+		announceAssignment(board, &goal, userID, previousAssignee)
+	}
+
 	// Broadcast goal update to other connected clients
 	if board.BoardType == "shared" {
 		WS.Broadcast(boardID, userID, WSEvent{
@@ -143,7 +213,10 @@ func UpdateGoal(c *fiber.Ctx) error {
 	return c.JSON(goal)
 }
 
-func ToggleGoalCompletion(c *fiber.Ctx) error {
+// MoveGoal moves a goal to another square, swapping with the goal already there.
+// Goals keep their IDs, so mini-goals, reflections, memories, comments and
+// per-member progress move with them.
+func MoveGoal(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	boardID, err := uuid.Parse(c.Params("boardId"))
 	if err != nil {
@@ -159,20 +232,122 @@ func ToggleGoalCompletion(c *fiber.Ctx) error {
 		})
 	}
 
-	var board models.Board
-	if err := database.DB.First(&board, boardID).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
+	board, _, err := authorizeBoard(c, boardID, permEditGoals)
+	if err != nil {
+		return err
+	}
+
+	var req models.MoveGoalRequest
+	if err := c.BodyParser(&req); err != nil || req.TargetPosition == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "targetPosition is required",
+		})
+	}
+	target := *req.TargetPosition
+
+	maxPosition := board.GridSize*board.GridSize - 1
+	if position > maxPosition || target < 0 || target > maxPosition {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid position for this board's grid size",
+		})
+	}
+	if target == position {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Goal is already in that position",
 		})
 	}
 
-	// Check access: owner or member
-	if board.UserID != userID && !isBoardMember(boardID, userID) {
+	var goal models.Goal
+	if err := database.DB.Where("board_id = ? AND position = ?", boardID, position).First(&goal).Error; err != nil {
 		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
+			"error": "Goal not found",
 		})
 	}
 
+	var swapped *models.Goal
+	var other models.Goal
+	if err := database.DB.Where("board_id = ? AND position = ?", boardID, target).First(&other).Error; err == nil {
+		swapped = &other
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&goal).Update("position", target).Error; err != nil {
+			return err
+		}
+		if swapped != nil {
+			if err := tx.Model(swapped).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+
+		// Keep the board's record of where the grace square is in step
+		if goal.IsGraceSquare {
+			return tx.Model(board).Update("grace_square_position", target).Error
+		}
+		if swapped != nil && swapped.IsGraceSquare {
+			return tx.Model(board).Update("grace_square_position", position).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to move goal",
+		})
+	}
+
+	metadata := map[string]interface{}{
+		"from": position,
+		"to":   target,
+	}
+	if swapped != nil {
+		metadata["swappedWith"] = swapped.ID.String()
+	}
+	LogActivity(boardID, userID, "goal_moved", &goal.ID, metadata)
+	syncBoardMilestones(board, &goal.ID)
+
+	if board.BoardType == "shared" {
+		moved := []models.Goal{goal}
+		if swapped != nil {
+			moved = append(moved, *swapped)
+		}
+		for _, g := range moved {
+			WS.Broadcast(boardID, userID, WSEvent{
+				Type:    EventGoalUpdated,
+				BoardID: boardID.String(),
+				UserID:  userID.String(),
+				Data:    g,
+			})
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"goal":        goal,
+		"swappedWith": swapped,
+		"milestones":  milestonesByMember(board),
+	})
+}
+
+func ToggleGoalCompletion(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("boardId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	position, err := strconv.Atoi(c.Params("position"))
+	if err != nil || position < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid position",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, boardID, permTrackProgress)
+	if err != nil {
+		return err
+	}
+
 	maxPosition := board.GridSize*board.GridSize - 1
 	if position > maxPosition {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -187,16 +362,24 @@ func ToggleGoalCompletion(c *fiber.Ctx) error {
 		})
 	}
 
+	if goal.IsGraceSquare {
+		return errGraceSquareLocked
+	}
+	if goal.Recurrence != nil {
+		return toggleHabit(c, *board, goal, userID)
+	}
+	if goal.TargetValue != nil {
+		return errCheckInGoalToggle
+	}
+
 	if board.BoardType == "shared" {
-		return toggleGoalForMember(c, board, goal, userID, position)
+		return toggleGoalForMember(c, *board, goal, userID, position)
 	}
-	return toggleGoalPersonal(c, board, goal, userID, position)
+	return toggleGoalPersonal(c, *board, goal, userID, position)
 }
 
 // toggleGoalPersonal handles goal toggling for personal boards (unchanged behavior).
 func toggleGoalPersonal(c *fiber.Ctx, board models.Board, goal models.Goal, userID uuid.UUID, position int) error {
-	boardID := board.ID
-
 	var miniGoals []models.MiniGoal
 	database.DB.Where("goal_id = ?", goal.ID).Find(&miniGoals)
 
@@ -244,13 +427,14 @@ func toggleGoalPersonal(c *fiber.Ctx, board models.Board, goal models.Goal, user
 			"error": "Failed to toggle goal",
 		})
 	}
+	goal.CompletedBy = syncCompletedBy(goal.ID, &userID)
 
 	gemsAwarded := 0
 	milestones := []string{}
 	if goal.Status == "completed" && !wasCompleted {
-		gemsAwarded, milestones = calculateMilestonesAndGems(boardID, board.GridSize, position)
-		awardGemsAndStreak(userID, gemsAwarded)
-		createBlankReflection(goal.ID)
+		gemsAwarded, milestones = goalCompleted(board, goal, userID)
+	} else if wasCompleted && goal.Status != "completed" {
+		completionUndone(&board, goal.ID, userID)
 	}
 
 	return c.JSON(fiber.Map{
@@ -331,6 +515,8 @@ func toggleGoalForMember(c *fiber.Ctx, board models.Board, goal models.Goal, use
 		}
 	}
 
+	goal.CompletedBy = syncCompletedBy(goal.ID, &userID)
+
 	// Broadcast toggle to other members
 	WS.Broadcast(boardID, userID, WSEvent{
 		Type:    EventGoalUpdated,
@@ -342,43 +528,9 @@ func toggleGoalForMember(c *fiber.Ctx, board models.Board, goal models.Goal, use
 	gemsAwarded := 0
 	milestones := []string{}
 	if gm.Status == "completed" && !wasCompleted {
-		// Calculate milestones from this user's GoalMember rows
-		gemsAwarded, milestones = calculateMemberMilestones(boardID, board.GridSize, position, userID)
-		awardGemsAndStreak(userID, gemsAwarded)
-		createBlankReflection(goal.ID)
-
-		// Log activity + notify other members
-		goalTitle := ""
-		if goal.Title != nil {
-			goalTitle = *goal.Title
-		}
-		LogActivity(boardID, userID, "goal_completed", &goal.ID, map[string]interface{}{
-			"goalTitle": goalTitle,
-			"position":  goal.Position,
-		})
-
-		var completer models.User
-		database.DB.First(&completer, userID)
-		name := completer.DisplayName
-		if name == "" {
-			name = completer.Name
-		}
-		notifyBoardMembers(boardID, userID, "goal_completed",
-			"Goal completed!",
-			name+" completed \""+goalTitle+"\" on "+board.Title,
-			map[string]interface{}{"boardId": boardID.String(), "goalId": goal.ID.String()},
-		)
-
-		WS.Broadcast(boardID, userID, WSEvent{
-			Type:    EventGoalCompleted,
-			BoardID: boardID.String(),
-			UserID:  userID.String(),
-			Data: map[string]interface{}{
-				"goalTitle": goalTitle,
-				"position":  goal.Position,
-				"userName":  name,
-			},
-		})
+		gemsAwarded, milestones = goalCompleted(board, goal, userID)
+	} else if wasCompleted && gm.Status != "completed" {
+		completionUndone(&board, goal.ID, userID)
 	}
 
 	// Return goal with this user's status overlaid
@@ -394,26 +546,112 @@ func toggleGoalForMember(c *fiber.Ctx, board models.Board, goal models.Goal, use
 	})
 }
 
-// calculateMilestonesAndGems calculates milestones for personal boards using Goal.Status.
-func calculateMilestonesAndGems(boardID uuid.UUID, gridSize, position int) (int, []string) {
-	var boardGoals []models.Goal
-	database.DB.Where("board_id = ?", boardID).Find(&boardGoals)
+// goalCompleted runs everything that happens when a goal becomes complete for
+// a user: milestones and gems, the blank reflection and, on shared boards, the
+// activity entry, member notifications and completion broadcast.
+func goalCompleted(board models.Board, goal models.Goal, userID uuid.UUID) (int, []string) {
+	boardID := board.ID
 
-	completed := make(map[int]bool)
-	for _, g := range boardGoals {
-		if g.Status == "completed" {
-			completed[g.Position] = true
-		}
+	// Milestones come from the board's squares on personal boards and from
+	// this user's GoalMember rows on shared ones
+	hits := checkMilestones(&board, completedSquares(&board, userID), goal.Position)
+	hits = recordMilestones(&board, userID, &goal.ID, hits)
+	gemsAwarded, milestones := awardCompletionGems(board, goal, userID, hits)
+	createBlankReflection(goal.ID)
+	evaluateAchievements(userID, metricGoalsCompleted, metricMilestones, metricBlackouts, metricDailyStreak)
+
+	if board.BoardType != "shared" {
+		return gemsAwarded, milestones
 	}
 
-	return checkMilestones(completed, gridSize, position)
+	// Log activity + notify other members
+	goalTitle := ""
+	if goal.Title != nil {
+		goalTitle = *goal.Title
+	}
+	LogActivity(boardID, userID, "goal_completed", &goal.ID, map[string]interface{}{
+		"goalTitle": goalTitle,
+		"position":  goal.Position,
+	})
+
+	var completer models.User
+	database.DB.First(&completer, userID)
+	name := completer.DisplayName
+	if name == "" {
+		name = completer.Name
+	}
+	notifyBoardMembers(boardID, userID, "goal_completed",
+		"Goal completed!",
+		name+" completed \""+goalTitle+"\" on "+board.Title,
+		map[string]interface{}{"boardId": boardID.String(), "goalId": goal.ID.String()},
+	)
+
+	WS.Broadcast(boardID, userID, WSEvent{
+		Type:    EventGoalCompleted,
+		BoardID: boardID.String(),
+		UserID:  userID.String(),
+		Data: map[string]interface{}{
+			"goalTitle": goalTitle,
+			"position":  goal.Position,
+			"userName":  name,
+		},
+	})
+
+	return gemsAwarded, milestones
 }
 
-// calculateMemberMilestones calculates milestones for shared boards using GoalMember rows.
-func calculateMemberMilestones(boardID uuid.UUID, gridSize, position int, userID uuid.UUID) (int, []string) {
-	// Get all goals for this board
+// awardCompletionGems posts the gems for completing a goal and the milestones
+// it finished to the ledger and updates the streak. Anything already awarded
+// is skipped, so the totals only cover what was newly earned.
+func awardCompletionGems(board models.Board, goal models.Goal, userID uuid.UUID, hits []milestoneHit) (int, []string) {
+	goalID := goal.ID
+	awards := []gemAward{{
+		Amount:  models.CompletionGems[board.GridSize],
+		Reason:  models.GemReasonGoalCompleted,
+		BoardID: board.ID,
+		GoalID:  &goalID,
+		Key:     goalAwardKey(goalID),
+	}}
+	names := map[string]string{}
+	for _, hit := range hits {
+		line := hit.Line
+		names[line] = hit.Name
+		awards = append(awards, gemAward{
+			Amount:    hit.Gems,
+			Reason:    models.GemReasonMilestone,
+			BoardID:   board.ID,
+			GoalID:    &goalID,
+			Milestone: &line,
+			Key:       milestoneAwardKey(board.ID, line),
+		})
+	}
+
+	gemsAwarded := 0
+	milestones := []string{}
+	for _, award := range awardGemsAndStreak(userID, awards) {
+		gemsAwarded += award.Amount
+		if award.Milestone != nil {
+			milestones = append(milestones, names[*award.Milestone])
+		}
+	}
+	return gemsAwarded, milestones
+}
+
+// completedSquares maps the positions complete for a user: the board's own
+// status on personal boards, the user's GoalMember rows on shared ones.
+func completedSquares(board *models.Board, userID uuid.UUID) map[int]bool {
 	var boardGoals []models.Goal
-	database.DB.Where("board_id = ?", boardID).Find(&boardGoals)
+	database.DB.Where("board_id = ?", board.ID).Find(&boardGoals)
+
+	completed := make(map[int]bool)
+	if board.BoardType != "shared" {
+		for _, g := range boardGoals {
+			if g.Status == "completed" {
+				completed[g.Position] = true
+			}
+		}
+		return completed
+	}
 
 	goalIDs := make([]uuid.UUID, len(boardGoals))
 	goalPositions := make(map[uuid.UUID]int)
@@ -426,128 +664,45 @@ func calculateMemberMilestones(boardID uuid.UUID, gridSize, position int, userID
 	var goalMembers []models.GoalMember
 	database.DB.Where("goal_id IN ? AND user_id = ? AND is_completed = true", goalIDs, userID).Find(&goalMembers)
 
-	completed := make(map[int]bool)
 	for _, gm := range goalMembers {
 		if pos, ok := goalPositions[gm.GoalID]; ok {
 			completed[pos] = true
 		}
 	}
-
-	return checkMilestones(completed, gridSize, position)
-}
-
-// checkMilestones checks row/col/diagonal/corner/blackout completion.
-func checkMilestones(completed map[int]bool, gridSize, position int) (int, []string) {
-	gemsAwarded := 0
-	switch gridSize {
-	case 3:
-		gemsAwarded = 5
-	case 7:
-		gemsAwarded = 2
-	default:
-		gemsAwarded = 3
-	}
-
-	milestones := []string{}
-	row := position / gridSize
-	col := position % gridSize
-
-	rowDone := true
-	for c := 0; c < gridSize; c++ {
-		if !completed[row*gridSize+c] {
-			rowDone = false
-			break
-		}
-	}
-	if rowDone {
-		milestones = append(milestones, "row")
-		gemsAwarded += 10
-	}
-
-	colDone := true
-	for r := 0; r < gridSize; r++ {
-		if !completed[r*gridSize+col] {
-			colDone = false
-			break
-		}
-	}
-	if colDone {
-		milestones = append(milestones, "column")
-		gemsAwarded += 10
-	}
-
-	if row == col {
-		diagDone := true
-		for i := 0; i < gridSize; i++ {
-			if !completed[i*gridSize+i] {
-				diagDone = false
-				break
-			}
-		}
-		if diagDone {
-			milestones = append(milestones, "diagonal")
-			gemsAwarded += 10
-		}
-	}
-
-	if row+col == gridSize-1 {
-		antiDone := true
-		for i := 0; i < gridSize; i++ {
-			if !completed[i*gridSize+(gridSize-1-i)] {
-				antiDone = false
-				break
-			}
-		}
-		if antiDone {
-			milestones = append(milestones, "anti-diagonal")
-			gemsAwarded += 10
+	// The grace square has no GoalMember rows but counts for every member
+	for _, g := range boardGoals {
+		if g.IsGraceSquare {
+			completed[g.Position] = true
 		}
 	}
+	return completed
+}
 
-	corners := []int{0, gridSize - 1, (gridSize - 1) * gridSize, gridSize*gridSize - 1}
-	cornersDone := true
-	for _, p := range corners {
-		if !completed[p] {
-			cornersDone = false
-			break
+// awardGemsAndStreak posts gem awards to the ledger and updates the streak
+// for a user. Returns the awards that were new.
+func awardGemsAndStreak(userID uuid.UUID, awards []gemAward) []gemAward {
+	posted := []gemAward{}
+	for _, award := range awards {
+		if postGemAward(userID, award) {
+			posted = append(posted, award)
 		}
 	}
-	if cornersDone {
-		milestones = append(milestones, "corners")
-		gemsAwarded += 15
-	}
 
-	if len(completed) == gridSize*gridSize {
-		milestones = append(milestones, "blackout")
-		gemsAwarded += 50
-	}
-
-	return gemsAwarded, milestones
-}
-
-// awardGemsAndStreak gives gems and updates streak for a user.
-func awardGemsAndStreak(userID uuid.UUID, gemsAwarded int) {
 	var user models.User
 	if err := database.DB.First(&user, userID).Error; err != nil {
-		return
+		return posted
 	}
-	user.TotalGems += gemsAwarded
 
-	today := time.Now().Truncate(24 * time.Hour)
-	if user.LastActiveDate != nil {
-		lastActive := user.LastActiveDate.Truncate(24 * time.Hour)
-		daysSince := int(today.Sub(lastActive).Hours() / 24)
-		if daysSince == 1 {
-			user.DailyStreak++
-		} else if daysSince > 1 {
-			user.DailyStreak = 1
-		}
-	} else {
-		user.DailyStreak = 1
-	}
-	user.LastActiveDate = &today
+	now := time.Now()
+	applyStreakFreezes(&user, now)
+	recordDailyActivity(&user, now)
 
-	database.DB.Save(&user)
+	// Only the streak; the ledger owns total_gems
+	database.DB.Model(&user).Updates(map[string]interface{}{
+		"daily_streak":     user.DailyStreak,
+		"last_active_date": user.LastActiveDate,
+	})
+	return posted
 }
 
 // overlayMemberStatus replaces goal/mini-goal status fields with per-member
@@ -606,19 +761,35 @@ func overlayMemberStatus(goals []models.Goal, boardType string, userID uuid.UUID
 		}
 	}
 
+	// The grace square is complete for every member
+	var memberCount int64
+	for i := range goals {
+		if goals[i].IsGraceSquare {
+			database.DB.Model(&models.BoardMember{}).Where("board_id = ?", goals[i].BoardID).Count(&memberCount)
+			break
+		}
+	}
+
 	// Overlay per-member status onto each goal
 	for i := range goals {
+		if goals[i].IsGraceSquare {
+			goals[i].CompletedByCount = int(memberCount)
+			continue
+		}
+
 		gm, exists := gmMap[goals[i].ID]
 		if exists {
 			goals[i].Status = gm.Status
 			goals[i].IsCompleted = gm.IsCompleted
 			goals[i].Progress = gm.Progress
+			goals[i].CurrentValue = gm.CurrentValue
 			goals[i].CompletedAt = gm.CompletedAt
 		} else {
 			// No member row yet — show as not started for this user
 			goals[i].Status = "not_started"
 			goals[i].IsCompleted = false
 			goals[i].Progress = 0
+			goals[i].CurrentValue = 0
 			goals[i].CompletedAt = nil
 		}
 
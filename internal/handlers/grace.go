@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// defaultGraceSquareTitle is used when the board doesn't set GraceSquareTitle
+const defaultGraceSquareTitle = "Free Space"
+
+// graceSquarePosition decides where a new board's grace square goes, or nil for
+// none. Boards of 5x5 and up get one in the centre unless the request opts out;
+// choosing a position opts a 3x3 board in.
+func graceSquarePosition(req models.CreateBoardRequest, gridSize int) (*int, error) {
+	enabled := gridSize >= 5 || req.GraceSquarePosition != nil
+	if req.GraceSquare != nil {
+		enabled = *req.GraceSquare
+	}
+	if !enabled {
+		return nil, nil
+	}
+
+	position := gridSize * gridSize / 2
+	if req.GraceSquarePosition != nil {
+		position = *req.GraceSquarePosition
+		if position < 0 || position >= gridSize*gridSize {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Grace square position is outside the grid")
+		}
+	}
+	return &position, nil
+}
+
+// createGraceSquare stores the board's grace square, already completed.
+// On shared boards members never get GoalMember rows for it; it counts as
+// complete for everyone. Run inside a transaction.
+func createGraceSquare(tx *gorm.DB, board *models.Board) error {
+	if board.GraceSquarePosition == nil {
+		return nil
+	}
+
+	title := defaultGraceSquareTitle
+	if board.GraceSquareTitle != nil && *board.GraceSquareTitle != "" {
+		title = *board.GraceSquareTitle
+	}
+
+	now := time.Now()
+	goal := models.Goal{
+		BoardID:       board.ID,
+		Position:      *board.GraceSquarePosition,
+		Title:         &title,
+		Status:        "completed",
+		IsCompleted:   true,
+		IsGraceSquare: true,
+		Progress:      100,
+		CompletedAt:   &now,
+	}
+	return tx.Create(&goal).Error
+}
+
+// errGraceSquareLocked is returned when something tries to change a grace square's progress
+var errGraceSquareLocked = fiber.NewError(fiber.StatusBadRequest, "The grace square is always complete and can't be changed")
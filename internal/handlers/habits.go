@@ -0,0 +1,299 @@
+package handlers
+
+import (
+	"sort"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// periodsPerYear caps RequiredPeriods and is its default: a habit completes
+// after a full year of hit periods unless told otherwise
+var periodsPerYear = map[string]int{
+	models.RecurrenceDaily:   365,
+	models.RecurrenceWeekly:  52,
+	models.RecurrenceMonthly: 12,
+}
+
+// maxTimesPerPeriod is how many check-in days fit in one period
+var maxTimesPerPeriod = map[string]int{
+	models.RecurrenceDaily:   1,
+	models.RecurrenceWeekly:  7,
+	models.RecurrenceMonthly: 28,
+}
+
+// applyRecurrence validates and sets a goal's habit rule from an update request
+func applyRecurrence(goal *models.Goal, req models.UpdateGoalRequest) error {
+	if req.Recurrence != nil {
+		if *req.Recurrence == "" {
+			goal.Recurrence = nil
+			goal.TimesPerPeriod = 0
+			goal.RequiredPeriods = nil
+			return nil
+		}
+		if _, ok := periodsPerYear[*req.Recurrence]; !ok {
+			return fiber.NewError(fiber.StatusBadRequest, "Recurrence must be daily, weekly or monthly")
+		}
+		if goal.Recurrence == nil || *goal.Recurrence != *req.Recurrence {
+			// A new period length invalidates the old numbers
+			goal.TimesPerPeriod = 0
+			goal.RequiredPeriods = nil
+		}
+		goal.Recurrence = req.Recurrence
+	}
+	if goal.Recurrence == nil {
+		if req.TimesPerPeriod != nil || req.RequiredPeriods != nil {
+			return fiber.NewError(fiber.StatusBadRequest, "Set a recurrence first")
+		}
+		return nil
+	}
+	recurrence := *goal.Recurrence
+
+	if req.TimesPerPeriod != nil {
+		goal.TimesPerPeriod = *req.TimesPerPeriod
+	}
+	if goal.TimesPerPeriod == 0 {
+		goal.TimesPerPeriod = 1
+	}
+	if goal.TimesPerPeriod < 1 || goal.TimesPerPeriod > maxTimesPerPeriod[recurrence] {
+		return fiber.NewError(fiber.StatusBadRequest, "timesPerPeriod is out of range for a "+recurrence+" habit")
+	}
+
+	if req.RequiredPeriods != nil {
+		goal.RequiredPeriods = req.RequiredPeriods
+	}
+	if goal.RequiredPeriods == nil {
+		n := periodsPerYear[recurrence]
+		goal.RequiredPeriods = &n
+	}
+	if *goal.RequiredPeriods < 1 || *goal.RequiredPeriods > periodsPerYear[recurrence] {
+		return fiber.NewError(fiber.StatusBadRequest, "requiredPeriods is out of range for a "+recurrence+" habit")
+	}
+	return nil
+}
+
+// toggleHabit checks the caller in for today on a habit goal, or takes
+// today's check-in back if there already is one
+func toggleHabit(c *fiber.Ctx, board models.Board, goal models.Goal, userID uuid.UUID) error {
+	loc := userLocation(userID)
+	now := time.Now().UTC()
+	local := now.In(loc)
+	dayStart := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc).UTC()
+	dayEnd := time.Date(local.Year(), local.Month(), local.Day()+1, 0, 0, 0, 0, loc).UTC()
+
+	var today []models.GoalCheckIn
+	database.DB.Where("goal_id = ? AND user_id = ? AND checked_in_at >= ? AND checked_in_at < ?",
+		goal.ID, userID, dayStart, dayEnd).Find(&today)
+
+	checkedIn := len(today) == 0
+	if checkedIn {
+		err := database.DB.Create(&models.GoalCheckIn{
+			GoalID:      goal.ID,
+			UserID:      userID,
+			Value:       1,
+			CheckedInAt: now,
+		}).Error
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error": "Failed to toggle goal",
+			})
+		}
+	} else if err := database.DB.Delete(&today).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to toggle goal",
+		})
+	}
+
+	completed := recalculateCheckInProgress(&board, &goal, userID)
+
+	gemsAwarded := 0
+	milestones := []string{}
+	if completed {
+		gemsAwarded, milestones = goalCompleted(board, goal, userID)
+	}
+	broadcastCheckIn(&board, &goal, userID)
+	goals := []models.Goal{goal}
+	attachStreaks(goals, &board, userID)
+
+	return c.JSON(fiber.Map{
+		"goal":           goals[0],
+		"checkedInToday": checkedIn,
+		"gemsAwarded":    gemsAwarded,
+		"milestones":     milestones,
+	})
+}
+
+// localDate is the calendar day t falls on in loc, as midnight UTC so dates
+// can be compared and stepped without DST surprises
+func localDate(t time.Time, loc *time.Location) time.Time {
+	l := t.In(loc)
+	return time.Date(l.Year(), l.Month(), l.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// periodStart is the first day of the period containing day. Weeks start on Monday.
+func periodStart(day time.Time, recurrence string) time.Time {
+	switch recurrence {
+	case models.RecurrenceWeekly:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case models.RecurrenceMonthly:
+		return time.Date(day.Year(), day.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+// shiftPeriod moves a period start by n periods
+func shiftPeriod(start time.Time, recurrence string, n int) time.Time {
+	switch recurrence {
+	case models.RecurrenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case models.RecurrenceMonthly:
+		return start.AddDate(0, n, 0)
+	default:
+		return start.AddDate(0, 0, n)
+	}
+}
+
+// habitStreak works out hit periods and streaks from check-in times. A period
+// is hit once check-ins land on TimesPerPeriod different days. The current
+// period still counts towards the streak while it's in progress.
+func habitStreak(goal *models.Goal, times []time.Time, loc *time.Location, now time.Time) models.GoalStreak {
+	recurrence := *goal.Recurrence
+
+	days := map[time.Time]map[time.Time]bool{}
+	for _, t := range times {
+		day := localDate(t, loc)
+		start := periodStart(day, recurrence)
+		if days[start] == nil {
+			days[start] = map[time.Time]bool{}
+		}
+		days[start][day] = true
+	}
+
+	var hits []time.Time
+	for start, d := range days {
+		if len(d) >= goal.TimesPerPeriod {
+			hits = append(hits, start)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool { return hits[i].Before(hits[j]) })
+
+	streak := models.GoalStreak{PeriodsHit: len(hits)}
+	run := 0
+	for i, start := range hits {
+		if i > 0 && shiftPeriod(hits[i-1], recurrence, 1).Equal(start) {
+			run++
+		} else {
+			run = 1
+		}
+		if run > streak.LongestStreak {
+			streak.LongestStreak = run
+		}
+	}
+	if len(hits) > 0 {
+		last := hits[len(hits)-1]
+		streak.LastHitPeriod = &last
+		streak.CurrentStreak = run
+	}
+	streak.CurrentStreak = liveStreak(streak, recurrence, loc, now)
+	return streak
+}
+
+// liveStreak is a stored streak as of now: it's broken once a whole period
+// has gone by without being hit
+func liveStreak(streak models.GoalStreak, recurrence string, loc *time.Location, now time.Time) int {
+	if streak.LastHitPeriod == nil {
+		return 0
+	}
+	current := periodStart(localDate(now, loc), recurrence)
+	if streak.LastHitPeriod.Before(shiftPeriod(current, recurrence, -1)) {
+		return 0
+	}
+	return streak.CurrentStreak
+}
+
+// habitProgress recalculates a habit goal for one user and stores their
+// streak. Progress is the share of RequiredPeriods hit so far.
+func habitProgress(goal *models.Goal, subjectID uuid.UUID, filter *uuid.UUID) (float64, int, string) {
+	query := database.DB.Model(&models.GoalCheckIn{}).Where("goal_id = ?", goal.ID)
+	if filter != nil {
+		query = query.Where("user_id = ?", *filter)
+	}
+	var times []time.Time
+	query.Pluck("checked_in_at", &times)
+
+	loc := userLocation(subjectID)
+	computed := habitStreak(goal, times, loc, time.Now())
+
+	var streak models.GoalStreak
+	if err := database.DB.Where("goal_id = ? AND user_id = ?", goal.ID, subjectID).First(&streak).Error; err != nil {
+		streak = models.GoalStreak{GoalID: goal.ID, UserID: subjectID}
+	}
+	streak.CurrentStreak = computed.CurrentStreak
+	streak.LongestStreak = computed.LongestStreak
+	streak.PeriodsHit = computed.PeriodsHit
+	streak.LastHitPeriod = computed.LastHitPeriod
+	if streak.ID == (uuid.UUID{}) {
+		database.DB.Create(&streak)
+	} else {
+		database.DB.Save(&streak)
+	}
+
+	required := periodsPerYear[*goal.Recurrence]
+	if goal.RequiredPeriods != nil {
+		required = *goal.RequiredPeriods
+	}
+	hits := computed.PeriodsHit
+	switch {
+	case hits >= required:
+		return float64(hits), 100, "completed"
+	case len(times) == 0:
+		return 0, 0, "not_started"
+	default:
+		progress := hits * 100 / required
+		return float64(hits), progress, "in_progress"
+	}
+}
+
+// attachStreaks sets the viewer's streak on each habit goal: their own on
+// shared boards, the owner's on personal ones
+func attachStreaks(goals []models.Goal, board *models.Board, userID uuid.UUID) {
+	subjectID := userID
+	if board.BoardType != "shared" {
+		subjectID = board.UserID
+	}
+
+	var goalIDs []uuid.UUID
+	for _, g := range goals {
+		if g.Recurrence != nil {
+			goalIDs = append(goalIDs, g.ID)
+		}
+	}
+	if len(goalIDs) == 0 {
+		return
+	}
+
+	var streaks []models.GoalStreak
+	database.DB.Where("goal_id IN ? AND user_id = ?", goalIDs, subjectID).Find(&streaks)
+	byGoal := make(map[uuid.UUID]models.GoalStreak, len(streaks))
+	for _, s := range streaks {
+		byGoal[s.GoalID] = s
+	}
+
+	loc := userLocation(subjectID)
+	now := time.Now()
+	for i := range goals {
+		if goals[i].Recurrence == nil {
+			continue
+		}
+		streak, ok := byGoal[goals[i].ID]
+		if !ok {
+			streak = models.GoalStreak{GoalID: goals[i].ID, UserID: subjectID}
+		}
+		streak.CurrentStreak = liveStreak(streak, *goals[i].Recurrence, loc, now)
+		goals[i].Streak = &streak
+	}
+}
@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ListIdentities returns the sign-in methods linked to the current user
+func ListIdentities(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var identities []models.UserIdentity
+	database.DB.Where("user_id = ?", userID).Order("created_at ASC").Find(&identities)
+
+	return c.JSON(fiber.Map{
+		"providers":   loginProviders(user),
+		"hasPassword": user.Password != "",
+		"identities":  identities,
+	})
+}
+
+// LinkIdentity links an external provider to the current (already authenticated) user.
+// This is the explicit confirmation path when GoogleLogin refuses to auto-link.
+func LinkIdentity(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var req models.LinkIdentityRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Provider != models.ProviderGoogle {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Unsupported provider. Must be: google",
+		})
+	}
+	if req.IDToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "ID token is required",
+		})
+	}
+
+	tokenInfo, err := services.Google.Verify(req.IDToken)
+	if err != nil {
+		return googleVerifyError(c, err)
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var existing models.UserIdentity
+	if err := database.DB.Where("provider = ? AND subject = ?", req.Provider, tokenInfo.Subject).First(&existing).Error; err == nil {
+		if existing.UserID == userID {
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": "This Google account is already linked",
+			})
+		}
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "This Google account is linked to another user",
+		})
+	}
+
+	if err := database.DB.Where("user_id = ? AND provider = ? AND subject NOT LIKE ?", userID, req.Provider, models.LegacySubjectPrefix+"%").
+		First(&existing).Error; err == nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "A different Google account is already linked. Unlink it first.",
+		})
+	}
+
+	if err := linkIdentity(&user, req.Provider, tokenInfo.Subject, tokenInfo.Email); err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to link account",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"providers": loginProviders(user),
+	})
+}
+
+// UnlinkIdentity removes a linked provider, refusing to remove the last way to sign in
+func UnlinkIdentity(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	provider := c.Params("provider")
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var identity models.UserIdentity
+	if err := database.DB.Where("user_id = ? AND provider = ?", userID, provider).First(&identity).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Provider not linked",
+		})
+	}
+
+	if len(loginProviders(user)) <= 1 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Set a password or link another provider before unlinking your only sign-in method",
+		})
+	}
+
+	// Hard delete so the same account can be linked again later
+	if err := database.DB.Unscoped().Delete(&identity).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to unlink provider",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// linkIdentity records a provider identity for the user. Google has verified
+// the address, so the user's email is marked verified if it wasn't already.
+func linkIdentity(user *models.User, provider, subject, email string) error {
+	// A backfilled identity without a subject gets the real one
+	var legacy models.UserIdentity
+	if err := database.DB.Where("user_id = ? AND provider = ? AND subject LIKE ?", user.ID, provider, models.LegacySubjectPrefix+"%").
+		First(&legacy).Error; err == nil {
+		if err := database.DB.Model(&legacy).Updates(map[string]interface{}{"subject": subject, "email": email}).Error; err != nil {
+			return err
+		}
+	} else {
+		identity := models.UserIdentity{
+			UserID:   user.ID,
+			Provider: provider,
+			Subject:  subject,
+			Email:    email,
+		}
+		if err := database.DB.Create(&identity).Error; err != nil {
+			return err
+		}
+	}
+
+	if user.EmailVerifiedAt == nil && email == user.Email {
+		now := time.Now()
+		database.DB.Model(user).Update("email_verified_at", now)
+		user.EmailVerifiedAt = &now
+	}
+	return nil
+}
+
+// loginProviders lists the ways a user can sign in: "email" when a password
+// is set, plus every linked identity provider.
+func loginProviders(user models.User) []string {
+	providers := []string{}
+	if user.Password != "" {
+		providers = append(providers, models.ProviderEmail)
+	}
+
+	var linked []string
+	database.DB.Model(&models.UserIdentity{}).
+		Where("user_id = ?", user.ID).
+		Order("created_at ASC").
+		Pluck("provider", &linked)
+
+	return append(providers, linked...)
+}
+
+// legacyAuthProvider is the single provider /api/me used to report as
+// authProvider: "email" for accounts with a password, otherwise the first linked
+// provider. Kept for older clients until they move to authProviders.
+func legacyAuthProvider(providers []string) string {
+	if len(providers) == 0 {
+		return models.ProviderEmail
+	}
+	return providers[0]
+}
@@ -8,9 +8,10 @@ import (
 	"github.com/arnold/bingoals-api/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
-// CreateInvite generates an invite code for a board (owner only)
+// CreateInvite generates an invite code for a board (admins and owner)
 func CreateInvite(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	boardID, err := uuid.Parse(c.Params("id"))
@@ -20,21 +21,33 @@ func CreateInvite(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify user is the board owner
-	var board models.Board
-	if err := database.DB.Where("id = ? AND user_id = ?", boardID, userID).First(&board).Error; err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found or you are not the owner",
-		})
+	_, role, err := authorizeBoard(c, boardID, permManageInvites)
+	if err != nil {
+		return err
 	}
 
 	var req models.CreateInviteRequest
 	c.BodyParser(&req) // optional body
 
+	if req.Role == "" {
+		req.Role = models.RoleEditor
+	}
+	if !models.IsAssignableRole(req.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role. Must be: admin, editor, or viewer",
+		})
+	}
+	if !canGrantRole(role, req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can't invite people with that role",
+		})
+	}
+
 	invite := models.BoardInvite{
 		BoardID:   boardID,
 		InviterID: userID,
 		MaxUses:   req.MaxUses,
+		Role:      req.Role,
 	}
 
 	if req.ExpiresIn > 0 {
@@ -96,10 +109,14 @@ func JoinBoard(c *fiber.Ctx) error {
 	}
 
 	// Create membership
+	role := invite.Role
+	if !models.IsAssignableRole(role) {
+		role = models.RoleEditor
+	}
 	member := models.BoardMember{
 		BoardID: invite.BoardID,
 		UserID:  userID,
-		Role:    "member",
+		Role:    role,
 	}
 	if err := database.DB.Create(&member).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -112,6 +129,7 @@ func JoinBoard(c *fiber.Ctx) error {
 
 	// Log activity
 	LogActivity(invite.BoardID, userID, "member_joined", nil, nil)
+	evaluateAchievements(board.UserID, metricBoardsShared)
 
 	// Notify other board members
 	var joiner models.User
@@ -139,12 +157,12 @@ func JoinBoard(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "Successfully joined board",
 		"boardId": invite.BoardID,
+		"role":    role,
 	})
 }
 
 // GetMembers lists all members of a board
 func GetMembers(c *fiber.Ctx) error {
-	userID := middleware.GetUserID(c)
 	boardID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
@@ -152,11 +170,8 @@ func GetMembers(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify user has access to this board
-	if !isBoardMember(boardID, userID) {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	if _, _, err := authorizeBoard(c, boardID, permViewBoard); err != nil {
+		return err
 	}
 
 	var members []models.BoardMember
@@ -179,7 +194,7 @@ func GetMembers(c *fiber.Ctx) error {
 	return c.JSON(result)
 }
 
-// RemoveMember removes a member from a board (owner only)
+// RemoveMember removes a member from a board (admins and owner, lower roles only)
 func RemoveMember(c *fiber.Ctx) error {
 	userID := middleware.GetUserID(c)
 	boardID, err := uuid.Parse(c.Params("id"))
@@ -196,20 +211,28 @@ func RemoveMember(c *fiber.Ctx) error {
 		})
 	}
 
-	// Verify caller is the board owner
-	var board models.Board
-	if err := database.DB.Where("id = ? AND user_id = ?", boardID, userID).First(&board).Error; err != nil {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "Only the board owner can remove members",
-		})
+	board, role, err := authorizeBoard(c, boardID, permManageMembers)
+	if err != nil {
+		return err
 	}
 
-	// Can't remove yourself (owner)
-	if targetUserID == userID {
+	// The owner can't be removed
+	if targetUserID == board.UserID {
 		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"error": "Owner cannot be removed. Transfer ownership first or delete the board.",
 		})
 	}
+	if targetUserID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Use leave to remove yourself from the board",
+		})
+	}
+
+	if targetRole := boardRole(board, targetUserID); targetRole != "" && !canManageRole(role, targetRole) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can only remove members with a lower role than yours",
+		})
+	}
 
 	result := database.DB.Where("board_id = ? AND user_id = ?", boardID, targetUserID).Delete(&models.BoardMember{})
 	if result.RowsAffected == 0 {
@@ -218,6 +241,8 @@ func RemoveMember(c *fiber.Ctx) error {
 		})
 	}
 
+	unassignMemberGoals(boardID, targetUserID)
+
 	LogActivity(boardID, targetUserID, "member_left", nil, map[string]interface{}{
 		"removedBy": userID,
 	})
@@ -263,6 +288,8 @@ func LeaveBoard(c *fiber.Ctx) error {
 		})
 	}
 
+	unassignMemberGoals(boardID, userID)
+
 	LogActivity(boardID, userID, "member_left", nil, nil)
 
 	// Broadcast member left via WebSocket
@@ -275,14 +302,216 @@ func LeaveBoard(c *fiber.Ctx) error {
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
-// isBoardMember checks if a user is a member of a board (owner or member)
-func isBoardMember(boardID, userID uuid.UUID) bool {
-	// Check ownership first
-	var board models.Board
-	if err := database.DB.Where("id = ? AND user_id = ?", boardID, userID).First(&board).Error; err == nil {
-		return true
+// UpdateMemberRole changes a member's role. Admins manage editors and viewers;
+// only the owner can promote to or demote from admin.
+func UpdateMemberRole(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	targetUserID, err := uuid.Parse(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req models.UpdateMemberRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	if !models.IsAssignableRole(req.Role) {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid role. Must be: admin, editor, or viewer",
+		})
+	}
+
+	board, role, err := authorizeBoard(c, boardID, permManageMembers)
+	if err != nil {
+		return err
 	}
-	// Check membership
+
+	if targetUserID == board.UserID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "The owner's role can't be changed. Transfer ownership instead.",
+		})
+	}
+
 	var member models.BoardMember
-	return database.DB.Where("board_id = ? AND user_id = ?", boardID, userID).First(&member).Error == nil
+	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, targetUserID).First(&member).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Member not found",
+		})
+	}
+
+	if !canManageRole(role, member.Role) || !canGrantRole(role, req.Role) {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": "You can only assign roles below your own to members below you",
+		})
+	}
+
+	previousRole := member.Role
+	if previousRole == req.Role {
+		return c.JSON(member)
+	}
+
+	if err := database.DB.Model(&member).Update("role", req.Role).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to update role",
+		})
+	}
+
+	if !roleAllows(req.Role, permTrackProgress) {
+		// Viewers can't work on goals, so they can't hold assignments either
+		unassignMemberGoals(boardID, targetUserID)
+	}
+
+	LogActivity(boardID, userID, "member_role_changed", &targetUserID, map[string]interface{}{
+		"from": previousRole,
+		"to":   req.Role,
+	})
+
+	CreateNotification(targetUserID, "member_role_changed",
+		"Your role changed",
+		"Your role on "+board.Title+" is now "+req.Role,
+		map[string]interface{}{"boardId": boardID.String(), "role": req.Role},
+	)
+
+	WS.Broadcast(boardID, userID, WSEvent{
+		Type:    EventMemberRoleChanged,
+		BoardID: boardID.String(),
+		UserID:  userID.String(),
+		Data: map[string]interface{}{
+			"memberId": targetUserID.String(),
+			"role":     req.Role,
+		},
+	})
+
+	return c.JSON(member)
+}
+
+// TransferOwnership hands a board to another member (owner only). The previous
+// owner stays on the board as an admin.
+func TransferOwnership(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	var req models.TransferOwnershipRequest
+	if err := c.BodyParser(&req); err != nil || req.UserID == uuid.Nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "userId is required",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, boardID, permTransferBoard)
+	if err != nil {
+		return err
+	}
+
+	if req.UserID == userID {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "You already own this board",
+		})
+	}
+
+	var target models.BoardMember
+	if err := database.DB.Where("board_id = ? AND user_id = ?", boardID, req.UserID).First(&target).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "New owner must be a member of this board",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return transferBoardOwnership(tx, board, req.UserID)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to transfer ownership",
+		})
+	}
+
+	announceOwnershipTransfer(*board, userID, userID, req.UserID, "")
+
+	return c.JSON(fiber.Map{
+		"message": "Ownership transferred",
+		"boardId": board.ID,
+		"ownerId": board.UserID,
+	})
+}
+
+// transferBoardOwnership moves Board.UserID to newOwnerID and swaps the two
+// members' roles, demoting the previous owner to admin. The new owner must
+// already be a member. Run inside a transaction.
+func transferBoardOwnership(tx *gorm.DB, board *models.Board, newOwnerID uuid.UUID) error {
+	previousOwnerID := board.UserID
+
+	// Each user has one default board; don't steal the new owner's
+	if err := tx.Model(&models.Board{}).Where("id = ?", board.ID).
+		Updates(map[string]interface{}{"user_id": newOwnerID, "is_default": false}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.BoardMember{}).
+		Where("board_id = ? AND user_id = ?", board.ID, previousOwnerID).
+		Update("role", models.RoleAdmin).Error; err != nil {
+		return err
+	}
+	result := tx.Model(&models.BoardMember{}).
+		Where("board_id = ? AND user_id = ?", board.ID, newOwnerID).
+		Update("role", models.RoleOwner)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+
+	board.UserID = newOwnerID
+	board.IsDefault = false
+	return nil
+}
+
+// announceOwnershipTransfer logs, notifies the new owner and tells connected
+// clients to refresh permissions. Call after the transfer has committed.
+func announceOwnershipTransfer(board models.Board, actorID, previousOwnerID, newOwnerID uuid.UUID, reason string) {
+	metadata := map[string]interface{}{
+		"previousOwnerId": previousOwnerID,
+	}
+	if reason != "" {
+		metadata["reason"] = reason
+	}
+	LogActivity(board.ID, actorID, "ownership_transferred", &newOwnerID, metadata)
+
+	CreateNotification(newOwnerID, "ownership_transferred",
+		"You're now the owner",
+		"You are now the owner of "+board.Title,
+		map[string]interface{}{"boardId": board.ID.String()},
+	)
+
+	// Nobody is excluded: the previous owner's other sessions must drop their owner controls too
+	WS.Broadcast(board.ID, uuid.Nil, WSEvent{
+		Type:    EventOwnershipTransferred,
+		BoardID: board.ID.String(),
+		UserID:  actorID.String(),
+		Data: map[string]interface{}{
+			"previousOwnerId": previousOwnerID.String(),
+			"newOwnerId":      newOwnerID.String(),
+		},
+	})
+}
+
+// isBoardMember checks if a user has any role on a board
+func isBoardMember(boardID, userID uuid.UUID) bool {
+	_, _, err := checkBoardPermission(boardID, userID, permViewBoard)
+	return err == nil
 }
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -14,7 +15,7 @@ import (
 // JournalEntry represents a single timeline item in the journal.
 type JournalEntry struct {
 	ID         string    `json:"id"`
-	Type       string    `json:"type"` // goal_completed, milestone_reached, reflection_added
+	Type       string    `json:"type"` // goal_completed, milestone_reached, line_completed, reflection_added, board_rolled_over
 	GoalTitle  string    `json:"goalTitle"`
 	BoardTitle string    `json:"boardTitle"`
 	BoardID    string    `json:"boardId"`
@@ -41,7 +42,7 @@ func GetJournal(c *fiber.Ctx) error {
 
 	// Build board title lookup.
 	var boards []models.Board
-	database.DB.Where("id IN ?", allBoardIDs).Select("id, title").Find(&boards)
+	database.DB.Where("id IN ?", allBoardIDs).Select("id, title, year, source_board_id, created_at").Find(&boards)
 	boardTitle := map[string]string{}
 	for _, b := range boards {
 		boardTitle[b.ID.String()] = b.Title
@@ -74,7 +75,7 @@ func GetJournal(c *fiber.Ctx) error {
 	var completedGoals []models.Goal
 	database.DB.
 		Preload("Memories").
-		Where("board_id IN ? AND is_completed = true", allBoardIDs).
+		Where("board_id IN ? AND is_completed = true AND is_grace_square = ?", allBoardIDs, false).
 		Order("completed_at DESC").
 		Limit(60).
 		Find(&completedGoals)
@@ -176,6 +177,67 @@ func GetJournal(c *fiber.Ctx) error {
 		}
 	}
 
+	// ── 4. Bingo lines and other milestone patterns ─────────────────────────
+	var boardMilestones []models.BoardMilestone
+	database.DB.
+		Where("board_id IN ? AND user_id = ? AND revoked_at IS NULL", allBoardIDs, userID).
+		Order("reached_at DESC").
+		Limit(40).
+		Find(&boardMilestones)
+
+	for _, m := range boardMilestones {
+		gt := ""
+		if m.GoalID != nil {
+			if t := goalTitleMap[m.GoalID.String()]; t != nil {
+				gt = *t
+			}
+		}
+		entries = append(entries, JournalEntry{
+			ID:         "line_" + m.ID.String(),
+			Type:       "line_completed",
+			GoalTitle:  gt,
+			BoardTitle: boardTitle[m.BoardID.String()],
+			BoardID:    m.BoardID.String(),
+			Content:    m.Line,
+			Timestamp:  m.ReachedAt,
+		})
+	}
+
+	// ── 5. Boards carried over from a previous year ─────────────────────────
+	var sourceIDs []uuid.UUID
+	for _, b := range boards {
+		if b.SourceBoardID != nil {
+			sourceIDs = append(sourceIDs, *b.SourceBoardID)
+		}
+	}
+	if len(sourceIDs) > 0 {
+		// Unscoped so a deleted previous year still shows its title
+		var sources []models.Board
+		database.DB.Unscoped().Where("id IN ?", sourceIDs).Select("id, title, year").Find(&sources)
+		sourceByID := map[uuid.UUID]models.Board{}
+		for _, s := range sources {
+			sourceByID[s.ID] = s
+		}
+
+		for _, b := range boards {
+			if b.SourceBoardID == nil {
+				continue
+			}
+			content := "Continued from last year's board"
+			if s, ok := sourceByID[*b.SourceBoardID]; ok {
+				content = fmt.Sprintf("Continued from %s (%d)", s.Title, s.Year)
+			}
+			entries = append(entries, JournalEntry{
+				ID:         "rollover_" + b.ID.String(),
+				Type:       "board_rolled_over",
+				BoardTitle: b.Title,
+				BoardID:    b.ID.String(),
+				Content:    content,
+				Timestamp:  b.CreatedAt,
+			})
+		}
+	}
+
 	// Sort newest first.
 	sort.Slice(entries, func(i, j int) bool {
 		return entries[i].Timestamp.After(entries[j].Timestamp)
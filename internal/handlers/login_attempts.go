@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	lockoutThreshold = 5         // consecutive failures before the first lockout
+	lockoutMaxDelay  = time.Hour // cap on the progressive back-off
+	lockoutLookback  = 24 * time.Hour
+)
+
+// loginLockedFor returns how long logins for this email are still locked out.
+// After lockoutThreshold consecutive failures each further failure doubles the
+// wait (1, 2, 4 ... minutes), capped at lockoutMaxDelay. A success resets it.
+func loginLockedFor(email string) time.Duration {
+	email = normalizeEmail(email)
+	since := time.Now().Add(-lockoutLookback)
+
+	var lastSuccess models.LoginAttempt
+	if err := database.DB.
+		Where("email = ? AND success = ? AND created_at > ?", email, true, since).
+		Order("created_at DESC").
+		First(&lastSuccess).Error; err == nil {
+		since = lastSuccess.CreatedAt
+	}
+
+	var failures []models.LoginAttempt
+	database.DB.
+		Where("email = ? AND success = ? AND created_at > ?", email, false, since).
+		Order("created_at DESC").
+		Limit(lockoutThreshold + 10).
+		Find(&failures)
+	if len(failures) < lockoutThreshold {
+		return 0
+	}
+
+	delay := lockoutMaxDelay
+	if n := len(failures) - lockoutThreshold; n < 6 {
+		delay = time.Duration(1<<n) * time.Minute
+	}
+
+	remaining := time.Until(failures[0].CreatedAt.Add(delay))
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// recordLoginAttempt stores an audit row for a password login attempt
+func recordLoginAttempt(c *fiber.Ctx, email string, success bool) {
+	database.DB.Create(&models.LoginAttempt{
+		Email:     normalizeEmail(email),
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		Success:   success,
+	})
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// LoginAttemptRetention is how long login attempts are kept for auditing;
+// set from config in main. Never shorter than the lockout lookback.
+var LoginAttemptRetention = 90 * 24 * time.Hour
+
+const jobPurgeLoginAttempts = "purge_login_attempts"
+
+// RegisterLoginAttemptJobs registers the daily login attempt purge and queues
+// it if it isn't already
+func RegisterLoginAttemptJobs() {
+	services.RegisterJobHandler(jobPurgeLoginAttempts, purgeLoginAttempts)
+
+	var queued int64
+	database.DB.Model(&models.ScheduledJob{}).Where("kind = ?", jobPurgeLoginAttempts).Count(&queued)
+	if queued == 0 {
+		if _, err := services.ScheduleJob(database.DB, jobPurgeLoginAttempts, nil, time.Now(), nil); err != nil {
+			log.Printf("Failed to queue login attempt purge: %v", err)
+		}
+	}
+}
+
+// purgeLoginAttempts deletes attempts older than the retention period, then runs again in a day
+func purgeLoginAttempts(job *models.ScheduledJob) (*time.Time, error) {
+	retention := LoginAttemptRetention
+	if retention < lockoutLookback {
+		retention = lockoutLookback
+	}
+
+	result := database.DB.Where("created_at < ?", time.Now().Add(-retention)).Delete(&models.LoginAttempt{})
+	if result.Error != nil {
+		log.Printf("Failed to purge login attempts: %v", result.Error)
+	} else if result.RowsAffected > 0 {
+		log.Printf("Purged %d login attempts", result.RowsAffected)
+	}
+
+	next := time.Now().Add(24 * time.Hour)
+	return &next, nil
+}
@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// patternKeyFormat is what a custom pattern's key may look like. Keys double
+// as ledger line names, so they can't contain spaces like "row 2" does.
+var patternKeyFormat = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// milestoneHit is a pattern line finished by completing a square
+type milestoneHit struct {
+	Name  string // the pattern's key: row, column, diagonal, corners, x, ...
+	Index int    // which row or column for "each" patterns, otherwise 0
+	Line  string // which line, as completedLines names it: "row 2", "diagonal"
+	Gems  int
+}
+
+// patternLine is one concrete set of squares a pattern covers on a grid.
+// Patterns with an "each" part have one line per row or column; the rest have one.
+type patternLine struct {
+	Pattern   models.MilestonePattern
+	Index     int
+	Label     string
+	Positions []int
+}
+
+// GetMilestonePatterns lists the pattern catalogue with the squares each line
+// covers on a grid of the given size (default 5), and which are the defaults
+func GetMilestonePatterns(c *fiber.Ctx) error {
+	gridSize, _ := strconv.Atoi(c.Query("gridSize", "5"))
+	if gridSize != 3 && gridSize != 5 && gridSize != 7 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Grid size must be 3, 5 or 7",
+		})
+	}
+
+	type lineResponse struct {
+		Label     string `json:"label"`
+		Positions []int  `json:"positions"`
+	}
+	type patternResponse struct {
+		models.MilestonePattern
+		Default bool           `json:"default"`
+		Lines   []lineResponse `json:"lines"`
+	}
+
+	defaults := map[string]bool{}
+	for _, key := range models.DefaultMilestonePatterns {
+		defaults[key] = true
+	}
+	patterns := []patternResponse{}
+	for _, p := range models.MilestonePatternCatalogue {
+		if p.MinGridSize > gridSize {
+			continue
+		}
+		lines := []lineResponse{}
+		for _, line := range patternLines(p, gridSize) {
+			lines = append(lines, lineResponse{line.Label, line.Positions})
+		}
+		patterns = append(patterns, patternResponse{p, defaults[p.Key], lines})
+	}
+
+	return c.JSON(fiber.Map{
+		"gridSize":       gridSize,
+		"completionGems": models.CompletionGems[gridSize],
+		"patterns":       patterns,
+	})
+}
+
+// findMilestonePattern looks a key up in the catalogue
+func findMilestonePattern(key string) (models.MilestonePattern, bool) {
+	for _, p := range models.MilestonePatternCatalogue {
+		if p.Key == key {
+			return p, true
+		}
+	}
+	return models.MilestonePattern{}, false
+}
+
+// validateMilestonePatterns checks a board's pattern selection and custom
+// patterns against its grid size, returning them cleaned up for storage
+func validateMilestonePatterns(keys []string, custom []models.MilestonePattern, gridSize int) ([]string, []models.MilestonePattern, error) {
+	selected := []string{}
+	seen := map[string]bool{}
+	for _, key := range keys {
+		p, ok := findMilestonePattern(key)
+		if !ok {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Unknown milestone pattern \""+key+"\"")
+		}
+		if p.MinGridSize > gridSize {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Milestone pattern \"%s\" needs a grid of at least %dx%d", key, p.MinGridSize, p.MinGridSize))
+		}
+		if seen[key] {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Milestone pattern \""+key+"\" is selected more than once")
+		}
+		seen[key] = true
+		selected = append(selected, key)
+	}
+
+	if len(custom) > models.MaxCustomPatterns {
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest,
+			fmt.Sprintf("A board can have at most %d custom patterns", models.MaxCustomPatterns))
+	}
+	patterns := []models.MilestonePattern{}
+	customKeys := map[string]bool{}
+	for _, p := range custom {
+		if !patternKeyFormat.MatchString(p.Key) {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest,
+				"Custom pattern keys must be lowercase letters, digits and dashes")
+		}
+		if _, ok := findMilestonePattern(p.Key); ok || customKeys[p.Key] {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Milestone pattern \""+p.Key+"\" already exists")
+		}
+		customKeys[p.Key] = true
+		if p.Name == "" {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Custom pattern \""+p.Key+"\" needs a name")
+		}
+		positions, err := patternPositions(p.Positions, gridSize)
+		if err != nil {
+			return nil, nil, err
+		}
+		if max := maxCustomPatternGems(len(positions), gridSize); p.Gems < 1 || p.Gems > max {
+			return nil, nil, fiber.NewError(fiber.StatusBadRequest,
+				fmt.Sprintf("Custom pattern \"%s\" can pay between 1 and %d gems", p.Key, max))
+		}
+		patterns = append(patterns, models.MilestonePattern{
+			Key:       p.Key,
+			Name:      p.Name,
+			Gems:      p.Gems,
+			Positions: positions,
+		})
+	}
+	return selected, patterns, nil
+}
+
+// maxCustomPatternGems caps a custom pattern at what a row pays per square
+// on the grid, so small patterns can't pay out more than the catalogue does
+func maxCustomPatternGems(squares, gridSize int) int {
+	row, _ := findMilestonePattern("row")
+	max := row.Gems * squares / gridSize
+	if max < 1 {
+		return 1
+	}
+	return max
+}
+
+// patternPositions checks a custom pattern's squares are on the grid and
+// returns them sorted without duplicates
+func patternPositions(positions []int, gridSize int) ([]int, error) {
+	unique := map[int]bool{}
+	for _, pos := range positions {
+		if pos < 0 || pos >= gridSize*gridSize {
+			return nil, fiber.NewError(fiber.StatusBadRequest, "Pattern position "+strconv.Itoa(pos)+" is outside the grid")
+		}
+		unique[pos] = true
+	}
+	if len(unique) < 2 {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "A custom pattern needs at least 2 squares")
+	}
+	return sortedPositions(unique), nil
+}
+
+func sortedPositions(set map[int]bool) []int {
+	positions := make([]int, 0, len(set))
+	for pos := range set {
+		positions = append(positions, pos)
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// boardMilestonePatterns is everything a board pays out for: its selection
+// from the catalogue, or the defaults if it has none, plus its custom patterns
+func boardMilestonePatterns(board *models.Board) []models.MilestonePattern {
+	keys := board.MilestonePatterns
+	if len(keys) == 0 {
+		keys = models.DefaultMilestonePatterns
+	}
+	patterns := []models.MilestonePattern{}
+	for _, key := range keys {
+		if p, ok := findMilestonePattern(key); ok && p.MinGridSize <= board.GridSize {
+			patterns = append(patterns, p)
+		}
+	}
+	// Custom patterns saved before the cap existed pay no more than it allows
+	for _, p := range board.CustomPatterns {
+		if max := maxCustomPatternGems(len(p.Positions), board.GridSize); p.Gems > max {
+			p.Gems = max
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns
+}
+
+// boardPatternLines expands the board's patterns into lines on its grid
+func boardPatternLines(board *models.Board) []patternLine {
+	lines := []patternLine{}
+	for _, p := range boardMilestonePatterns(board) {
+		lines = append(lines, patternLines(p, board.GridSize)...)
+	}
+	return lines
+}
+
+// patternLines expands a pattern into the lines it covers on a grid. Custom
+// patterns are their positions; shape patterns are the union of their parts,
+// once per row or column when a part is "each".
+func patternLines(p models.MilestonePattern, gridSize int) []patternLine {
+	if len(p.Positions) > 0 {
+		return []patternLine{{p, 0, p.Key, p.Positions}}
+	}
+
+	each := false
+	for _, part := range p.Parts {
+		if part.Index == models.PartEach {
+			each = true
+		}
+	}
+	if !each {
+		return []patternLine{{p, 0, p.Key, partPositions(p.Parts, gridSize, 0)}}
+	}
+
+	lines := make([]patternLine, 0, gridSize)
+	for i := 0; i < gridSize; i++ {
+		lines = append(lines, patternLine{p, i, fmt.Sprintf("%s %d", p.Key, i+1), partPositions(p.Parts, gridSize, i)})
+	}
+	return lines
+}
+
+// partPositions lists the squares covered by a pattern's parts; each is the
+// row or column "each" parts stand for
+func partPositions(parts []models.PatternPart, gridSize, each int) []int {
+	covered := map[int]bool{}
+	index := func(i string) int {
+		switch i {
+		case models.PartFirst:
+			return 0
+		case models.PartMiddle:
+			return gridSize / 2
+		case models.PartLast:
+			return gridSize - 1
+		default:
+			return each
+		}
+	}
+	last := gridSize*gridSize - 1
+	for _, part := range parts {
+		switch part.Shape {
+		case models.ShapeRow:
+			r := index(part.Index)
+			for i := 0; i < gridSize; i++ {
+				covered[r*gridSize+i] = true
+			}
+		case models.ShapeColumn:
+			col := index(part.Index)
+			for i := 0; i < gridSize; i++ {
+				covered[i*gridSize+col] = true
+			}
+		case models.ShapeDiagonal:
+			for i := 0; i < gridSize; i++ {
+				covered[i*gridSize+i] = true
+			}
+		case models.ShapeAntiDiagonal:
+			for i := 0; i < gridSize; i++ {
+				covered[i*gridSize+(gridSize-1-i)] = true
+			}
+		case models.ShapeCorners:
+			covered[0], covered[gridSize-1], covered[last-(gridSize-1)], covered[last] = true, true, true, true
+		case models.ShapeAll:
+			for i := 0; i <= last; i++ {
+				covered[i] = true
+			}
+		}
+	}
+	return sortedPositions(covered)
+}
+
+func lineComplete(line patternLine, completed map[int]bool) bool {
+	for _, pos := range line.Positions {
+		if !completed[pos] {
+			return false
+		}
+	}
+	return true
+}
+
+// checkMilestones returns the board's pattern lines through position that are
+// now complete. Lines finished earlier are included too; recordMilestones
+// picks out the ones that are new.
+func checkMilestones(board *models.Board, completed map[int]bool, position int) []milestoneHit {
+	hits := []milestoneHit{}
+	for _, line := range boardPatternLines(board) {
+		if !containsPosition(line.Positions, position) || !lineComplete(line, completed) {
+			continue
+		}
+		hits = append(hits, milestoneHit{line.Pattern.Key, line.Index, line.Label, line.Pattern.Gems})
+	}
+	return hits
+}
+
+// completedHits lists every complete pattern line on the board as a hit
+func completedHits(board *models.Board, completed map[int]bool) []milestoneHit {
+	hits := []milestoneHit{}
+	for _, line := range boardPatternLines(board) {
+		if lineComplete(line, completed) {
+			hits = append(hits, milestoneHit{line.Pattern.Key, line.Index, line.Label, line.Pattern.Gems})
+		}
+	}
+	return hits
+}
+
+// completedLines names every complete pattern line on the board
+func completedLines(board *models.Board, completed map[int]bool) []string {
+	lines := []string{}
+	for _, hit := range completedHits(board, completed) {
+		lines = append(lines, hit.Line)
+	}
+	return lines
+}
+
+// recordMilestones stores the lines a user just completed as BoardMilestones
+// and returns the ones they hadn't already reached. goalID is the goal that
+// finished them, nil when they came together some other way.
+func recordMilestones(board *models.Board, userID uuid.UUID, goalID *uuid.UUID, hits []milestoneHit) []milestoneHit {
+	reached := []milestoneHit{}
+	now := time.Now()
+	for _, hit := range hits {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			var existing int64
+			tx.Model(&models.BoardMilestone{}).
+				Where("board_id = ? AND user_id = ? AND pattern = ? AND line_index = ? AND revoked_at IS NULL",
+					board.ID, userID, hit.Name, hit.Index).
+				Count(&existing)
+			if existing > 0 {
+				return errAlreadyAwarded
+			}
+			return tx.Create(&models.BoardMilestone{
+				BoardID:   board.ID,
+				UserID:    userID,
+				Pattern:   hit.Name,
+				LineIndex: hit.Index,
+				Line:      hit.Line,
+				Gems:      hit.Gems,
+				GoalID:    goalID,
+				ReachedAt: now,
+			}).Error
+		})
+		if err == nil {
+			reached = append(reached, hit)
+		}
+	}
+	return reached
+}
+
+// revokeStaleMilestones revokes a board's live milestones whose line is no
+// longer among the completed lines; nil userID checks every user's
+func revokeStaleMilestones(boardID uuid.UUID, userID *uuid.UUID, lines []string) {
+	query := database.DB.Model(&models.BoardMilestone{}).
+		Where("board_id = ? AND revoked_at IS NULL", boardID)
+	if userID != nil {
+		query = query.Where("user_id = ?", *userID)
+	}
+	if len(lines) > 0 {
+		query = query.Where("line NOT IN ?", lines)
+	}
+	query.Update("revoked_at", time.Now())
+}
+
+// syncBoardMilestones brings every member's milestones in line with the
+// board after goals move, get cleared or trashed, or its patterns change:
+// lines no longer complete are revoked and their gems taken back, and lines
+// that came together are recorded and paid. goalID is the goal that moved, if any.
+func syncBoardMilestones(board *models.Board, goalID *uuid.UUID) {
+	for _, id := range milestoneMembers(board) {
+		hits := completedHits(board, completedSquares(board, id))
+		lines := make([]string, len(hits))
+		for i, hit := range hits {
+			lines[i] = hit.Line
+		}
+
+		// Personal boards check every award, as completionUndone does
+		userID := &id
+		if board.BoardType != "shared" {
+			userID = nil
+		}
+		revokeStaleMilestones(board.ID, userID, lines)
+		reverseStaleMilestones(board.ID, userID, lines)
+
+		reached := recordMilestones(board, id, goalID, hits)
+		for _, hit := range reached {
+			line := hit.Line
+			postGemAward(id, gemAward{
+				Amount:    hit.Gems,
+				Reason:    models.GemReasonMilestone,
+				BoardID:   board.ID,
+				GoalID:    goalID,
+				Milestone: &line,
+				Key:       milestoneAwardKey(board.ID, line),
+			})
+		}
+		if len(reached) > 0 {
+			evaluateAchievements(id, metricMilestones, metricBlackouts)
+		}
+	}
+}
+
+// recordCarriedMilestones records the lines already complete on a board
+// rolled over with its progress, without paying for them: their gems were
+// earned on the old board. Breaking and finishing one again pays as usual.
+func recordCarriedMilestones(board *models.Board) {
+	for _, id := range milestoneMembers(board) {
+		recordMilestones(board, id, nil, completedHits(board, completedSquares(board, id)))
+	}
+}
+
+func containsPosition(positions []int, position int) bool {
+	for _, pos := range positions {
+		if pos == position {
+			return true
+		}
+	}
+	return false
+}
+
+// resizeMilestonePatterns fits a board's patterns to a new grid: selected
+// patterns too big for it are dropped, and custom patterns move with their
+// squares or are dropped if any square doesn't fit. Returns the dropped keys.
+func resizeMilestonePatterns(board *models.Board, newSize int, strategy string, mapping map[int]int) ([]string, []models.MilestonePattern, []string) {
+	dropped := []string{}
+	selected := []string{}
+	for _, key := range board.MilestonePatterns {
+		if p, ok := findMilestonePattern(key); ok && p.MinGridSize <= newSize {
+			selected = append(selected, key)
+			continue
+		}
+		dropped = append(dropped, key)
+	}
+
+	custom := []models.MilestonePattern{}
+	for _, p := range board.CustomPatterns {
+		moved := map[int]bool{}
+		fits := true
+		for _, pos := range p.Positions {
+			to, ok := resizePosition(pos, board.GridSize, newSize, strategy, mapping)
+			if !ok {
+				fits = false
+				break
+			}
+			moved[to] = true
+		}
+		if !fits || len(moved) < 2 {
+			dropped = append(dropped, p.Key)
+			continue
+		}
+		p.Positions = sortedPositions(moved)
+		if max := maxCustomPatternGems(len(p.Positions), newSize); p.Gems > max {
+			p.Gems = max
+		}
+		custom = append(custom, p)
+	}
+	return selected, custom, dropped
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/arnold/bingoals-api/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 // effectivePercentages calculates display percentages for mini-goals.
@@ -41,6 +42,15 @@ func effectivePercentages(miniGoals []models.MiniGoal) []float64 {
 }
 
 func recalculateGoalProgress(goalID uuid.UUID) {
+	if progressFromCheckIns(goalID) {
+		return
+	}
+
+	var goal models.Goal
+	if err := database.DB.First(&goal, goalID).Error; err != nil {
+		return
+	}
+
 	var miniGoals []models.MiniGoal
 	database.DB.Where("goal_id = ?", goalID).Find(&miniGoals)
 
@@ -73,54 +83,58 @@ func recalculateGoalProgress(goalID uuid.UUID) {
 	}
 
 	database.DB.Model(&models.Goal{}).Where("id = ?", goalID).Updates(updates)
+	syncCompletedBy(goalID, nil)
+	if goal.Status == "completed" && progress < 100 {
+		undoGoalCompletion(goalID, nil)
+	} else if goal.Status != "completed" && progress >= 100 {
+		payGoalCompletion(goalID, nil)
+	}
 }
 
 
-func findGoalByBoardAndPosition(c *fiber.Ctx) (*models.Goal, *models.Board, error) {
-	userID := middleware.GetUserID(c)
+// progressFromCheckIns reports whether a goal is numeric or a habit, in which
+// case its mini-goals are a checklist only
+func progressFromCheckIns(goalID uuid.UUID) bool {
+	var goal models.Goal
+	if err := database.DB.Select("target_value", "recurrence").First(&goal, goalID).Error; err != nil {
+		return false
+	}
+	return tracksCheckIns(&goal)
+}
+
+// findGoalByBoardAndPosition resolves :boardId/:position after checking the caller holds perm on the board
+func findGoalByBoardAndPosition(c *fiber.Ctx, perm boardPermission) (*models.Goal, *models.Board, error) {
 	boardID, err := uuid.Parse(c.Params("boardId"))
 	if err != nil {
-		return nil, nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid board ID",
-		})
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid board ID")
 	}
 
 	position, err := strconv.Atoi(c.Params("position"))
 	if err != nil || position < 0 {
-		return nil, nil, c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "Invalid position",
-		})
-	}
-
-	var board models.Board
-	if err := database.DB.Where("id = ?", boardID).First(&board).Error; err != nil {
-		return nil, nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+		return nil, nil, fiber.NewError(fiber.StatusBadRequest, "Invalid position")
 	}
 
-	// Check access: owner or member
-	if board.UserID != userID && !isBoardMember(boardID, userID) {
-		return nil, nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Board not found",
-		})
+	board, _, err := authorizeBoard(c, boardID, perm)
+	if err != nil {
+		return nil, nil, err
 	}
 
 	var goal models.Goal
 	if err := database.DB.Where("board_id = ? AND position = ?", boardID, position).First(&goal).Error; err != nil {
-		return nil, nil, c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "Goal not found",
-		})
+		return nil, nil, fiber.NewError(fiber.StatusNotFound, "Goal not found")
 	}
 
-	return &goal, &board, nil
+	return &goal, board, nil
 }
 
 func CreateMiniGoal(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
+	if goal.IsGraceSquare {
+		return errGraceSquareLocked
+	}
 
 	var req models.CreateMiniGoalRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -140,10 +154,19 @@ func CreateMiniGoal(c *fiber.Ctx) error {
 		})
 	}
 
+	var dueDate *time.Time
+	if req.DueDate != nil {
+		var err error
+		if dueDate, err = parseDueDate(*req.DueDate); err != nil {
+			return err
+		}
+	}
+
 	miniGoal := models.MiniGoal{
 		GoalID:     goal.ID,
 		Title:      req.Title,
 		Percentage: req.Percentage,
+		DueDate:    dueDate,
 	}
 
 	if err := database.DB.Create(&miniGoal).Error; err != nil {
@@ -158,7 +181,7 @@ func CreateMiniGoal(c *fiber.Ctx) error {
 }
 
 func ToggleMiniGoal(c *fiber.Ctx) error {
-	goal, board, fiberErr := findGoalByBoardAndPosition(c)
+	goal, board, fiberErr := findGoalByBoardAndPosition(c, permTrackProgress)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -218,8 +241,27 @@ func ToggleMiniGoal(c *fiber.Ctx) error {
 	return c.JSON(miniGoal)
 }
 
+// refreshGoalProgress recalculates a goal's progress after its mini-goals change:
+// the goal itself on personal boards, every member who has progress on shared ones
+func refreshGoalProgress(goalID uuid.UUID, boardType string) {
+	if boardType != "shared" {
+		recalculateGoalProgress(goalID)
+		return
+	}
+
+	var userIDs []uuid.UUID
+	database.DB.Model(&models.GoalMember{}).Where("goal_id = ?", goalID).Pluck("user_id", &userIDs)
+	for _, userID := range userIDs {
+		recalculateGoalProgressForMember(goalID, userID)
+	}
+}
+
 // recalculateGoalProgressForMember recalculates per-member goal progress from MiniGoalMember rows.
 func recalculateGoalProgressForMember(goalID, userID uuid.UUID) {
+	if progressFromCheckIns(goalID) {
+		return
+	}
+
 	var miniGoals []models.MiniGoal
 	database.DB.Where("goal_id = ?", goalID).Find(&miniGoals)
 
@@ -257,6 +299,7 @@ func recalculateGoalProgressForMember(goalID, userID uuid.UUID) {
 		gm = models.GoalMember{GoalID: goalID, UserID: userID}
 	}
 
+	wasCompleted := gm.IsCompleted
 	gm.Progress = progress
 	if progress >= 100 {
 		gm.Status = "completed"
@@ -278,10 +321,16 @@ func recalculateGoalProgressForMember(goalID, userID uuid.UUID) {
 	} else {
 		database.DB.Save(&gm)
 	}
+	syncCompletedBy(goalID, &userID)
+	if wasCompleted && !gm.IsCompleted {
+		undoGoalCompletion(goalID, &userID)
+	} else if !wasCompleted && gm.IsCompleted {
+		payGoalCompletion(goalID, &userID)
+	}
 }
 
 func UpdateMiniGoal(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -321,6 +370,13 @@ func UpdateMiniGoal(c *fiber.Ctx) error {
 		}
 		miniGoal.Percentage = req.Percentage
 	}
+	if req.DueDate != nil {
+		dueDate, err := parseDueDate(*req.DueDate)
+		if err != nil {
+			return err
+		}
+		miniGoal.DueDate = dueDate
+	}
 
 	if err := database.DB.Save(&miniGoal).Error; err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -328,13 +384,17 @@ func UpdateMiniGoal(c *fiber.Ctx) error {
 		})
 	}
 
+	if req.DueDate != nil {
+		rescheduleReminders("mini_goal_id = ?", miniGoal.ID)
+	}
+
 	recalculateGoalProgress(goal.ID)
 
 	return c.JSON(miniGoal)
 }
 
 func DeleteMiniGoal(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, board, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -353,13 +413,17 @@ func DeleteMiniGoal(c *fiber.Ctx) error {
 		})
 	}
 
-	if err := database.DB.Delete(&miniGoal).Error; err != nil {
+	// Goes to the trash with its per-member completion
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return softDeleteMiniGoals(tx, []uuid.UUID{miniGoal.ID}, trashTimestamp())
+	})
+	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": "Failed to delete mini-goal",
 		})
 	}
 
-	recalculateGoalProgress(goal.ID)
+	refreshGoalProgress(goal.ID, board.BoardType)
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
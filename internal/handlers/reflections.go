@@ -10,7 +10,7 @@ import (
 )
 
 func GetReflection(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, _, fiberErr := findGoalByBoardAndPosition(c, permViewBoard)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -26,7 +26,7 @@ func GetReflection(c *fiber.Ctx) error {
 }
 
 func UpsertReflection(c *fiber.Ctx) error {
-	goal, _, fiberErr := findGoalByBoardAndPosition(c)
+	goal, board, fiberErr := findGoalByBoardAndPosition(c, permEditGoals)
 	if fiberErr != nil {
 		return fiberErr
 	}
@@ -68,6 +68,11 @@ func UpsertReflection(c *fiber.Ctx) error {
 		})
 	}
 
+	// Reflections on personal boards count towards the owner's achievements
+	if board.BoardType != "shared" {
+		evaluateAchievements(board.UserID, metricReflections)
+	}
+
 	return c.JSON(reflection)
 }
 
@@ -0,0 +1,431 @@
+package handlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// jobGoalReminder is the scheduler job kind that fires a ReminderRule
+const jobGoalReminder = "goal_reminder"
+
+const defaultReminderTime = "09:00"
+
+// RegisterReminderJobs hooks reminder rules up to the scheduler
+func RegisterReminderJobs() {
+	services.RegisterJobHandler(jobGoalReminder, runGoalReminder)
+}
+
+// GetReminders lists the caller's reminders for a goal and its mini-goals
+func GetReminders(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	goal, _, err := findGoalByBoardAndPosition(c, permViewBoard)
+	if err != nil {
+		return err
+	}
+
+	var rules []models.ReminderRule
+	database.DB.Where("goal_id = ? AND user_id = ?", goal.ID, userID).Order("created_at ASC").Find(&rules)
+	withNextRun(rules)
+
+	return c.JSON(rules)
+}
+
+// CreateReminder adds a reminder for the caller. Anyone who can see the board
+// can remind themselves; reminders are never sent to other members.
+func CreateReminder(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	goal, board, err := findGoalByBoardAndPosition(c, permViewBoard)
+	if err != nil {
+		return err
+	}
+
+	var req models.CreateReminderRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	rule := models.ReminderRule{
+		UserID:     userID,
+		BoardID:    board.ID,
+		GoalID:     goal.ID,
+		MiniGoalID: req.MiniGoalID,
+		Kind:       req.Kind,
+		TimeOfDay:  req.TimeOfDay,
+	}
+	if rule.TimeOfDay == "" {
+		rule.TimeOfDay = defaultReminderTime
+	}
+	if _, _, ok := parseTimeOfDay(rule.TimeOfDay); !ok {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "timeOfDay must be HH:MM",
+		})
+	}
+
+	dueDate := goal.DueDate
+	if req.MiniGoalID != nil {
+		var miniGoal models.MiniGoal
+		if err := database.DB.Where("id = ? AND goal_id = ?", *req.MiniGoalID, goal.ID).First(&miniGoal).Error; err != nil {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": "Mini-goal not found",
+			})
+		}
+		dueDate = miniGoal.DueDate
+	}
+
+	switch req.Kind {
+	case models.ReminderBeforeDue:
+		if dueDate == nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "Set a due date before adding a before_due reminder",
+			})
+		}
+		if req.DaysBefore < 0 || req.DaysBefore > 365 {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "daysBefore must be between 0 and 365",
+			})
+		}
+		rule.DaysBefore = req.DaysBefore
+	case models.ReminderDaily:
+	case models.ReminderWeekly:
+		rule.Weekday = int(time.Monday)
+		if req.Weekday != nil {
+			if *req.Weekday < 0 || *req.Weekday > 6 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "weekday must be between 0 (Sunday) and 6 (Saturday)",
+				})
+			}
+			rule.Weekday = *req.Weekday
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Kind must be before_due, daily or weekly",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&rule).Error; err != nil {
+			return err
+		}
+		return scheduleReminder(tx, &rule, dueDate, userLocation(userID))
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to create reminder",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// DeleteReminder removes one of the caller's reminders
+func DeleteReminder(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	ruleID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid reminder ID",
+		})
+	}
+
+	var rule models.ReminderRule
+	if err := database.DB.Where("id = ? AND user_id = ?", ruleID, userID).First(&rule).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Reminder not found",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := services.CancelJobs(tx, jobGoalReminder, rule.ID); err != nil {
+			return err
+		}
+		return tx.Delete(&rule).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete reminder",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Reminder deleted",
+	})
+}
+
+// parseDueDate reads a YYYY-MM-DD due date. An empty string clears it.
+// Due dates are calendar days, stored as midnight UTC and read back in the
+// reminded user's timezone.
+func parseDueDate(s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	d, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fiber.NewError(fiber.StatusBadRequest, "dueDate must be YYYY-MM-DD")
+	}
+	return &d, nil
+}
+
+// parseTimeOfDay splits an HH:MM wall-clock time
+func parseTimeOfDay(s string) (int, int, bool) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, 0, false
+	}
+	return t.Hour(), t.Minute(), true
+}
+
+// userLocation is the user's timezone, falling back to UTC
+func userLocation(userID uuid.UUID) *time.Location {
+	var user models.User
+	if err := database.DB.Select("timezone").First(&user, userID).Error; err != nil {
+		return time.UTC
+	}
+	return user.Location()
+}
+
+// nextReminderRun returns the first time after `after` that a rule should
+// fire, or nil if it never will again. Daily and weekly reminders stop after
+// the due date when there is one.
+func nextReminderRun(rule *models.ReminderRule, dueDate *time.Time, loc *time.Location, after time.Time) *time.Time {
+	hour, minute, ok := parseTimeOfDay(rule.TimeOfDay)
+	if !ok {
+		return nil
+	}
+	dueDate = dueDateUTC(dueDate)
+
+	var next time.Time
+	switch rule.Kind {
+	case models.ReminderBeforeDue:
+		if dueDate == nil {
+			return nil
+		}
+		next = time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day()-rule.DaysBefore, hour, minute, 0, 0, loc)
+		if !next.After(after) {
+			return nil
+		}
+		return &next
+	case models.ReminderDaily, models.ReminderWeekly:
+		local := after.In(loc)
+		next = time.Date(local.Year(), local.Month(), local.Day(), hour, minute, 0, 0, loc)
+		for !next.After(after) || (rule.Kind == models.ReminderWeekly && int(next.Weekday()) != rule.Weekday) {
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, hour, minute, 0, 0, loc)
+		}
+	default:
+		return nil
+	}
+
+	if dueDate != nil {
+		dueEnd := time.Date(dueDate.Year(), dueDate.Month(), dueDate.Day()+1, 0, 0, 0, 0, loc)
+		if !next.Before(dueEnd) {
+			return nil
+		}
+	}
+	return &next
+}
+
+// scheduleReminder replaces any pending job for a rule with one at its next run
+func scheduleReminder(tx *gorm.DB, rule *models.ReminderRule, dueDate *time.Time, loc *time.Location) error {
+	if err := services.CancelJobs(tx, jobGoalReminder, rule.ID); err != nil {
+		return err
+	}
+	rule.NextRunAt = nil
+	next := nextReminderRun(rule, dueDate, loc, time.Now())
+	if next == nil {
+		return nil
+	}
+	job, err := services.ScheduleJob(tx, jobGoalReminder, &rule.ID, *next, nil)
+	if err != nil {
+		return err
+	}
+	rule.NextRunAt = &job.RunAt
+	return nil
+}
+
+// rescheduleReminders recomputes the next run of every rule matching the
+// query, e.g. after a due date or the user's timezone changes
+func rescheduleReminders(query interface{}, args ...interface{}) {
+	var rules []models.ReminderRule
+	database.DB.Where(query, args...).Find(&rules)
+
+	locations := map[uuid.UUID]*time.Location{}
+	for i := range rules {
+		rule := &rules[i]
+		loc, ok := locations[rule.UserID]
+		if !ok {
+			loc = userLocation(rule.UserID)
+			locations[rule.UserID] = loc
+		}
+		dueDate, _, err := reminderTarget(rule)
+		if err != nil {
+			continue // target is gone; the pending job drops itself when it fires
+		}
+		scheduleReminder(database.DB, rule, dueDate, loc)
+	}
+}
+
+// reminderTarget loads the due date and title of whatever a rule is about
+func reminderTarget(rule *models.ReminderRule) (*time.Time, string, error) {
+	var goal models.Goal
+	if err := database.DB.First(&goal, rule.GoalID).Error; err != nil {
+		return nil, "", err
+	}
+	if rule.MiniGoalID == nil {
+		title := ""
+		if goal.Title != nil {
+			title = *goal.Title
+		}
+		return goal.DueDate, title, nil
+	}
+
+	var miniGoal models.MiniGoal
+	if err := database.DB.Where("id = ? AND goal_id = ?", *rule.MiniGoalID, goal.ID).First(&miniGoal).Error; err != nil {
+		return nil, "", err
+	}
+	return miniGoal.DueDate, miniGoal.Title, nil
+}
+
+// hardDeleteReminders permanently removes the rules matching the query along
+// with their pending jobs. Run inside a transaction.
+func hardDeleteReminders(tx *gorm.DB, query interface{}, args ...interface{}) error {
+	var ids []uuid.UUID
+	tx.Unscoped().Model(&models.ReminderRule{}).Where(query, args...).Pluck("id", &ids)
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := services.CancelJobs(tx, jobGoalReminder, ids...); err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&models.ReminderRule{}).Error
+}
+
+// withNextRun fills in NextRunAt from each rule's pending job
+func withNextRun(rules []models.ReminderRule) {
+	if len(rules) == 0 {
+		return
+	}
+	ids := make([]uuid.UUID, len(rules))
+	for i, r := range rules {
+		ids[i] = r.ID
+	}
+
+	var jobs []models.ScheduledJob
+	database.DB.Where("kind = ? AND ref_id IN ?", jobGoalReminder, ids).Find(&jobs)
+	runAt := make(map[uuid.UUID]time.Time, len(jobs))
+	for _, j := range jobs {
+		runAt[*j.RefID] = j.RunAt
+	}
+	for i := range rules {
+		if t, ok := runAt[rules[i].ID]; ok {
+			rules[i].NextRunAt = &t
+		}
+	}
+}
+
+// runGoalReminder fires a reminder rule and returns when it should fire next.
+// Rules whose goal was deleted or whose user lost access to the board stop
+// quietly; reminders for something the user already finished are skipped.
+func runGoalReminder(job *models.ScheduledJob) (*time.Time, error) {
+	if job.RefID == nil {
+		return nil, nil
+	}
+	var rule models.ReminderRule
+	if err := database.DB.First(&rule, *job.RefID).Error; err != nil {
+		return nil, nil
+	}
+	board, _, err := checkBoardPermission(rule.BoardID, rule.UserID, permViewBoard)
+	if err != nil {
+		return nil, nil
+	}
+	dueDate, title, err := reminderTarget(&rule)
+	if err != nil {
+		return nil, nil
+	}
+
+	loc := userLocation(rule.UserID)
+	if !reminderTargetDone(board, &rule) {
+		var goal models.Goal
+		database.DB.Select("position").First(&goal, rule.GoalID)
+
+		metadata := map[string]interface{}{
+			"boardId":  rule.BoardID.String(),
+			"goalId":   rule.GoalID.String(),
+			"position": goal.Position,
+		}
+		if rule.MiniGoalID != nil {
+			metadata["miniGoalId"] = rule.MiniGoalID.String()
+		}
+		CreateNotification(rule.UserID, "goal_reminder", title, reminderBody(&rule, dueDate, loc), metadata)
+	}
+
+	return nextReminderRun(&rule, dueDate, loc, time.Now()), nil
+}
+
+// reminderTargetDone reports whether the reminded user has finished the goal or mini-goal
+func reminderTargetDone(board *models.Board, rule *models.ReminderRule) bool {
+	shared := board.BoardType == "shared"
+	if rule.MiniGoalID != nil {
+		if shared {
+			var mgm models.MiniGoalMember
+			err := database.DB.Where("mini_goal_id = ? AND user_id = ?", *rule.MiniGoalID, rule.UserID).First(&mgm).Error
+			return err == nil && mgm.IsComplete
+		}
+		var miniGoal models.MiniGoal
+		err := database.DB.First(&miniGoal, *rule.MiniGoalID).Error
+		return err == nil && miniGoal.IsComplete
+	}
+
+	if shared {
+		var gm models.GoalMember
+		err := database.DB.Where("goal_id = ? AND user_id = ?", rule.GoalID, rule.UserID).First(&gm).Error
+		return err == nil && gm.IsCompleted
+	}
+	var goal models.Goal
+	err := database.DB.First(&goal, rule.GoalID).Error
+	return err == nil && goal.IsCompleted
+}
+
+// dueDateUTC reads a due date in UTC. Due dates are stored as midnight UTC,
+// but drivers can hand them back in the database session's timezone, where
+// the calendar date may be the day before.
+func dueDateUTC(dueDate *time.Time) *time.Time {
+	if dueDate == nil {
+		return nil
+	}
+	due := dueDate.UTC()
+	return &due
+}
+
+// reminderBody describes how close the due date is, in the user's own calendar
+func reminderBody(rule *models.ReminderRule, dueDate *time.Time, loc *time.Location) string {
+	if dueDate == nil {
+		if rule.Kind == models.ReminderWeekly {
+			return "Your weekly check-in"
+		}
+		return "Your daily check-in"
+	}
+	dueDate = dueDateUTC(dueDate)
+
+	now := time.Now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(dueDate.Sub(today).Hours() / 24)
+	switch {
+	case days < 0:
+		return "Was due " + dueDate.Format("Jan 2")
+	case days == 0:
+		return "Due today"
+	case days == 1:
+		return "Due tomorrow"
+	default:
+		return fmt.Sprintf("Due in %d days (%s)", days, dueDate.Format("Mon Jan 2"))
+	}
+}
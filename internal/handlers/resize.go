@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ResizeBoard changes a board's grid size and moves its goals to new positions.
+// Goals that don't fit either block the resize or are archived, depending on
+// req.Overflow. Blank squares and a grace square that doesn't fit are always
+// dropped. Dropped goals that were completed lose their gems, and every
+// member's milestones are brought in line with the new grid.
+func ResizeBoard(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, boardID, permEditBoard)
+	if err != nil {
+		return err
+	}
+
+	var req models.ResizeBoardRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.GridSize != 3 && req.GridSize != 5 && req.GridSize != 7 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Grid size must be 3, 5 or 7",
+		})
+	}
+	if req.GridSize == board.GridSize {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": fmt.Sprintf("Board is already %dx%d", board.GridSize, board.GridSize),
+		})
+	}
+
+	if req.Strategy == "" {
+		req.Strategy = models.ResizeTopLeft
+	}
+	if req.Overflow == "" {
+		req.Overflow = models.ResizeOverflowRefuse
+	}
+	switch req.Strategy {
+	case models.ResizeTopLeft, models.ResizeCenter:
+	case models.ResizeMapping:
+		if err := validateResizeMapping(req.Mapping, req.GridSize); err != nil {
+			return err
+		}
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Strategy must be top_left, center or mapping",
+		})
+	}
+	if req.Overflow != models.ResizeOverflowRefuse && req.Overflow != models.ResizeOverflowArchive {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Overflow must be refuse or archive",
+		})
+	}
+
+	var goals []models.Goal
+	database.DB.Where("board_id = ?", boardID).Order("position ASC").Find(&goals)
+
+	newPositions := map[uuid.UUID]int{}
+	var dropped []uuid.UUID
+	archived := []int{}
+	var gracePosition *int
+	for _, g := range goals {
+		if pos, ok := resizePosition(g.Position, board.GridSize, req.GridSize, req.Strategy, req.Mapping); ok {
+			newPositions[g.ID] = pos
+			if g.IsGraceSquare {
+				p := pos
+				gracePosition = &p
+			}
+			continue
+		}
+		dropped = append(dropped, g.ID)
+		if g.Title != nil && *g.Title != "" && !g.IsGraceSquare {
+			archived = append(archived, g.Position)
+		}
+	}
+
+	if len(archived) > 0 && req.Overflow == models.ResizeOverflowRefuse {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error":     fmt.Sprintf("%d goals don't fit on a %dx%d grid", len(archived), req.GridSize, req.GridSize),
+			"positions": archived,
+		})
+	}
+
+	patterns, customPatterns, droppedPatterns := resizeMilestonePatterns(board, req.GridSize, req.Strategy, req.Mapping)
+
+	previousSize := board.GridSize
+	completions := goalCompletions(board, dropped)
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		for id, pos := range newPositions {
+			if err := tx.Model(&models.Goal{}).Where("id = ?", id).Update("position", pos).Error; err != nil {
+				return err
+			}
+		}
+		if err := softDeleteGoals(tx, dropped, trashTimestamp()); err != nil {
+			return err
+		}
+		// Saved from the struct so the patterns go through their JSON serializer
+		board.GridSize = req.GridSize
+		board.GraceSquarePosition = gracePosition
+		board.MilestonePatterns = patterns
+		board.CustomPatterns = customPatterns
+		return tx.Model(board).
+			Select("grid_size", "grace_square_position", "milestone_patterns", "custom_patterns").
+			Updates(board).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to resize board",
+		})
+	}
+	undoTrashedCompletions(board, completions)
+
+	LogActivity(boardID, userID, "board_resized", nil, map[string]interface{}{
+		"from":     previousSize,
+		"to":       req.GridSize,
+		"strategy": req.Strategy,
+		"archived": archived,
+	})
+	WS.Broadcast(boardID, userID, WSEvent{
+		Type:    EventBoardUpdated,
+		BoardID: boardID.String(),
+		UserID:  userID.String(),
+		Data: map[string]interface{}{
+			"gridSize": req.GridSize,
+		},
+	})
+
+	var updated models.Board
+	database.DB.Preload("Goals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Goals.MiniGoals").Preload("Members.User").First(&updated, boardID)
+	overlayMemberStatus(updated.Goals, updated.BoardType, userID)
+
+	return c.JSON(fiber.Map{
+		"board":           updated,
+		"archived":        archived,
+		"droppedPatterns": droppedPatterns,
+		"milestones":      milestonesByMember(&updated),
+	})
+}
+
+// validateResizeMapping checks every target is on the new grid and used once
+func validateResizeMapping(mapping map[int]int, gridSize int) error {
+	if len(mapping) == 0 {
+		return fiber.NewError(fiber.StatusBadRequest, "Mapping is required for the mapping strategy")
+	}
+	used := map[int]bool{}
+	for _, to := range mapping {
+		if to < 0 || to >= gridSize*gridSize {
+			return fiber.NewError(fiber.StatusBadRequest, "Mapping position "+strconv.Itoa(to)+" is outside the new grid")
+		}
+		if used[to] {
+			return fiber.NewError(fiber.StatusBadRequest, "Mapping uses position "+strconv.Itoa(to)+" more than once")
+		}
+		used[to] = true
+	}
+	return nil
+}
+
+// resizePosition returns where a square lands on the new grid, or false if it doesn't fit
+func resizePosition(position, oldSize, newSize int, strategy string, mapping map[int]int) (int, bool) {
+	if strategy == models.ResizeMapping {
+		to, ok := mapping[position]
+		return to, ok
+	}
+
+	row, col := position/oldSize, position%oldSize
+	if strategy == models.ResizeCenter {
+		// Grid sizes are all odd, so the offset is a whole number of squares
+		offset := (newSize - oldSize) / 2
+		row, col = row+offset, col+offset
+	}
+	if row < 0 || col < 0 || row >= newSize || col >= newSize {
+		return 0, false
+	}
+	return row*newSize + col, true
+}
+
+// milestonesByMember lists the complete lines on a board for each member,
+// keyed by user ID. Personal boards only report the owner.
+func milestonesByMember(board *models.Board) map[string][]string {
+	result := map[string][]string{}
+	for _, id := range milestoneMembers(board) {
+		result[id.String()] = completedLines(board, completedSquares(board, id))
+	}
+	return result
+}
+
+// milestoneMembers is everyone who reaches milestones on a board: the owner
+// of a personal board, every member of a shared one
+func milestoneMembers(board *models.Board) []uuid.UUID {
+	if board.BoardType != "shared" {
+		return []uuid.UUID{board.UserID}
+	}
+	var memberIDs []uuid.UUID
+	database.DB.Model(&models.BoardMember{}).Where("board_id = ?", board.ID).Pluck("user_id", &memberIDs)
+	return memberIDs
+}
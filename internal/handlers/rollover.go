@@ -0,0 +1,302 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// RolloverBoard starts next year's board from this one. The new board keeps
+// the grid, category and grace square and links back through SourceBoardID.
+// Memories, reflections, comments and activity stay with the old year.
+func RolloverBoard(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	source, _, err := authorizeBoard(c, boardID, permRolloverBoard)
+	if err != nil {
+		return err
+	}
+
+	var req models.RolloverBoardRequest
+	c.BodyParser(&req) // optional body
+
+	switch req.Mode {
+	case "":
+		req.Mode = models.RolloverUnfinished
+	case models.RolloverUnfinished, models.RolloverAll, models.RolloverTitles:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Mode must be unfinished, all or titles",
+		})
+	}
+	if req.Title == "" {
+		req.Title = rolloverTitle(source.Title, source.Year)
+	}
+
+	var successors int64
+	database.DB.Model(&models.Board{}).Where("source_board_id = ?", source.ID).Count(&successors)
+	if successors > 0 {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "This board has already been rolled over",
+		})
+	}
+
+	var goals []models.Goal
+	database.DB.Where("board_id = ? AND title IS NOT NULL AND title != ''", source.ID).
+		Preload("MiniGoals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Order("position ASC").
+		Find(&goals)
+
+	// Members other than the caller, who becomes the owner of the new board
+	var members []models.BoardMember
+	if req.IncludeMembers && source.BoardType == "shared" {
+		database.DB.Where("board_id = ? AND user_id != ?", source.ID, userID).Find(&members)
+	}
+
+	// The new board gets its own grace square wherever the old one had it, keeping its name
+	hasGraceSquare := source.GraceSquarePosition != nil
+	graceTitle := source.GraceSquareTitle
+	for _, g := range goals {
+		if g.IsGraceSquare {
+			graceTitle = g.Title
+		}
+	}
+
+	var board *models.Board
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		board, err = createBoard(tx, userID, models.CreateBoardRequest{
+			Title:               req.Title,
+			Year:                source.Year + 1,
+			GridSize:            source.GridSize,
+			Category:            source.Category,
+			BoardType:           source.BoardType,
+			MaxMembers:          source.MaxMembers,
+			GraceSquareTitle:    graceTitle,
+			GraceSquare:         &hasGraceSquare,
+			GraceSquarePosition: source.GraceSquarePosition,
+			MilestonePatterns:   source.MilestonePatterns,
+			CustomPatterns:      source.CustomPatterns,
+		}, nil)
+		if err != nil {
+			return err
+		}
+		if err := tx.Model(board).Update("source_board_id", source.ID).Error; err != nil {
+			return err
+		}
+
+		carried := []uuid.UUID{userID}
+		for _, m := range members {
+			role := m.Role
+			if role == models.RoleOwner {
+				role = models.RoleAdmin // stale row from before a transfer
+			}
+			if err := tx.Create(&models.BoardMember{BoardID: board.ID, UserID: m.UserID, Role: role}).Error; err != nil {
+				return err
+			}
+			carried = append(carried, m.UserID)
+		}
+
+		return rolloverGoals(tx, source, board, goals, req, userID, carried)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to roll over board",
+		})
+	}
+	recordCarriedMilestones(board)
+
+	LogActivity(source.ID, userID, "board_rolled_over", &board.ID, map[string]interface{}{
+		"year":  board.Year,
+		"title": board.Title,
+		"mode":  req.Mode,
+	})
+	for _, m := range members {
+		CreateNotification(m.UserID, "board_rolled_over",
+			fmt.Sprintf("%s continues in %d", source.Title, board.Year),
+			"You've been added to "+board.Title,
+			map[string]interface{}{"boardId": board.ID.String(), "sourceBoardId": source.ID.String()},
+		)
+	}
+
+	database.DB.Preload("Goals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Goals.MiniGoals").Preload("Members.User").First(board, board.ID)
+
+	return c.Status(fiber.StatusCreated).JSON(board)
+}
+
+// rolloverGoals copies the source board's goals onto the new board according to
+// req.Mode. On shared boards progress lives in GoalMember rows, so "unfinished"
+// means unfinished for the caller and only carried members keep their progress.
+// Run inside a transaction.
+func rolloverGoals(tx *gorm.DB, source, board *models.Board, goals []models.Goal, req models.RolloverBoardRequest, userID uuid.UUID, carried []uuid.UUID) error {
+	shared := source.BoardType == "shared"
+	keepProgress := req.Mode != models.RolloverTitles
+
+	// Per-member progress, keyed by goal or mini-goal ID
+	goalMembers := map[uuid.UUID][]models.GoalMember{}
+	miniGoalMembers := map[uuid.UUID][]models.MiniGoalMember{}
+	if shared && len(goals) > 0 {
+		goalIDs := make([]uuid.UUID, len(goals))
+		var miniGoalIDs []uuid.UUID
+		for i, g := range goals {
+			goalIDs[i] = g.ID
+			for _, mg := range g.MiniGoals {
+				miniGoalIDs = append(miniGoalIDs, mg.ID)
+			}
+		}
+
+		var gms []models.GoalMember
+		tx.Where("goal_id IN ? AND user_id IN ?", goalIDs, carried).Find(&gms)
+		for _, gm := range gms {
+			goalMembers[gm.GoalID] = append(goalMembers[gm.GoalID], gm)
+		}
+		if len(miniGoalIDs) > 0 {
+			var mgms []models.MiniGoalMember
+			tx.Where("mini_goal_id IN ? AND user_id IN ?", miniGoalIDs, carried).Find(&mgms)
+			for _, mgm := range mgms {
+				miniGoalMembers[mgm.MiniGoalID] = append(miniGoalMembers[mgm.MiniGoalID], mgm)
+			}
+		}
+	}
+
+	isCarried := make(map[uuid.UUID]bool, len(carried))
+	for _, id := range carried {
+		isCarried[id] = true
+	}
+
+	for _, g := range goals {
+		if g.IsGraceSquare {
+			continue // createBoard already made the new one
+		}
+		if req.Mode == models.RolloverUnfinished && rolloverGoalFinished(g, shared, goalMembers[g.ID], userID) {
+			continue
+		}
+
+		goal := models.Goal{
+			BoardID:         board.ID,
+			Position:        g.Position,
+			Title:           g.Title,
+			Description:     g.Description,
+			Icon:            g.Icon,
+			Mood:            g.Mood,
+			TargetValue:     g.TargetValue,
+			Unit:            g.Unit,
+			Recurrence:      g.Recurrence,
+			TimesPerPeriod:  g.TimesPerPeriod,
+			RequiredPeriods: g.RequiredPeriods,
+		}
+		// Numeric goals and habits count from zero again in the new year
+		fromCheckIns := tracksCheckIns(&g)
+		if keepProgress {
+			goal.ImageURL = g.ImageURL
+			if g.AssignedTo != nil && isCarried[*g.AssignedTo] {
+				goal.AssignedTo = g.AssignedTo
+			}
+			if !shared && !fromCheckIns {
+				goal.Status = g.Status
+				goal.IsCompleted = g.IsCompleted
+				goal.Progress = g.Progress
+				goal.CompletedAt = g.CompletedAt
+				goal.CompletedBy = g.CompletedBy
+				if !req.IncludeMiniGoals && !goal.IsCompleted {
+					// Partial progress came from the mini-goals that are left behind
+					goal.Status = "not_started"
+					goal.Progress = 0
+				}
+			}
+		}
+		if err := tx.Create(&goal).Error; err != nil {
+			return err
+		}
+
+		if shared && keepProgress && !fromCheckIns {
+			for _, gm := range goalMembers[g.ID] {
+				row := models.GoalMember{
+					GoalID:      goal.ID,
+					UserID:      gm.UserID,
+					Status:      gm.Status,
+					IsCompleted: gm.IsCompleted,
+					Progress:    gm.Progress,
+					CompletedAt: gm.CompletedAt,
+				}
+				if !req.IncludeMiniGoals && !row.IsCompleted {
+					row.Status = "not_started"
+					row.Progress = 0
+				}
+				if err := tx.Create(&row).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		if !req.IncludeMiniGoals {
+			continue
+		}
+		for _, mg := range g.MiniGoals {
+			miniGoal := models.MiniGoal{
+				GoalID:     goal.ID,
+				Title:      mg.Title,
+				Percentage: mg.Percentage,
+			}
+			if keepProgress {
+				miniGoal.ImageURL = mg.ImageURL
+				if !shared {
+					miniGoal.IsComplete = mg.IsComplete
+				}
+			}
+			if err := tx.Create(&miniGoal).Error; err != nil {
+				return err
+			}
+
+			if shared && keepProgress {
+				for _, mgm := range miniGoalMembers[mg.ID] {
+					row := models.MiniGoalMember{
+						MiniGoalID: miniGoal.ID,
+						UserID:     mgm.UserID,
+						IsComplete: mgm.IsComplete,
+					}
+					if err := tx.Create(&row).Error; err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// rolloverGoalFinished reports whether a goal counts as done for the caller
+func rolloverGoalFinished(goal models.Goal, shared bool, members []models.GoalMember, userID uuid.UUID) bool {
+	if !shared {
+		return goal.IsCompleted
+	}
+	for _, gm := range members {
+		if gm.UserID == userID {
+			return gm.IsCompleted
+		}
+	}
+	return false
+}
+
+// rolloverTitle bumps the year in a board title ("2025 Goals" becomes
+// "2026 Goals"); titles without the year are kept as they are
+func rolloverTitle(title string, year int) string {
+	return strings.ReplaceAll(title, strconv.Itoa(year), strconv.Itoa(year+1))
+}
@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// RefreshToken exchanges a refresh token for a new access token, rotating the refresh token
+func RefreshToken(c *fiber.Ctx) error {
+	var req models.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Refresh token is required",
+		})
+	}
+
+	tokenHash := hashToken(req.RefreshToken)
+
+	var session models.Session
+	if err := database.DB.Where("refresh_token_hash = ?", tokenHash).First(&session).Error; err != nil {
+		// A rotated-out token being replayed means it leaked — kill the whole session
+		if database.DB.Where("previous_token_hash = ?", tokenHash).First(&session).Error == nil {
+			revokeSession(session.ID)
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	if !session.IsActive() {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Session has expired or been revoked",
+		})
+	}
+
+	var user models.User
+	if err := database.DB.First(&user, session.UserID).Error; err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	newRefreshToken, err := generateRefreshToken()
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
+		})
+	}
+
+	// Conditional update so two concurrent refreshes with the same token can't both win
+	now := time.Now()
+	result := database.DB.Model(&models.Session{}).
+		Where("id = ? AND refresh_token_hash = ?", session.ID, tokenHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash":  hashToken(newRefreshToken),
+			"previous_token_hash": tokenHash,
+			"expires_at":          now.Add(middleware.RefreshTokenTTL),
+			"last_used_at":        now,
+			"user_agent":          c.Get("User-Agent"),
+			"ip_address":          c.IP(),
+		})
+	if result.Error != nil || result.RowsAffected == 0 {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"error": "Invalid refresh token",
+		})
+	}
+
+	token, err := middleware.GenerateToken(user.ID, session.ID, user.Email)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to generate token",
+		})
+	}
+
+	return c.JSON(models.AuthResponse{
+		Token:        token,
+		RefreshToken: newRefreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+		User:         user,
+	})
+}
+
+// Logout revokes the session behind the current access token
+func Logout(c *fiber.Ctx) error {
+	revokeSession(middleware.GetSessionID(c))
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// LogoutAll revokes every active session for the current user ("log out all devices")
+func LogoutAll(c *fiber.Ctx) error {
+	revoked := revokeAllSessions(middleware.GetUserID(c))
+	return c.JSON(fiber.Map{"revoked": revoked})
+}
+
+// issueSession creates a new session for the user and returns an access/refresh token pair
+func issueSession(c *fiber.Ctx, user models.User) (*models.AuthResponse, error) {
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: hashToken(refreshToken),
+		UserAgent:        c.Get("User-Agent"),
+		IPAddress:        c.IP(),
+		ExpiresAt:        now.Add(middleware.RefreshTokenTTL),
+		LastUsedAt:       now,
+	}
+	if err := database.DB.Create(&session).Error; err != nil {
+		return nil, err
+	}
+
+	token, err := middleware.GenerateToken(user.ID, session.ID, user.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.AuthResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(middleware.AccessTokenTTL.Seconds()),
+		User:         user,
+	}, nil
+}
+
+// revokeSession marks a single session as revoked
+func revokeSession(sessionID uuid.UUID) {
+	database.DB.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Update("revoked_at", time.Now())
+}
+
+// revokeAllSessions revokes every active session for a user and returns how many were revoked
+func revokeAllSessions(userID uuid.UUID) int64 {
+	result := database.DB.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected
+}
+
+func generateRefreshToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken returns the SHA-256 hex digest used to store opaque tokens at rest
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,258 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// errAlreadyApplied means another request used the streak freezes first
+var errAlreadyApplied = errors.New("streak freezes already applied")
+
+// activeDay reads a stored LastActiveDate as a calendar date. Dates are kept
+// as midnight UTC of the user's local day.
+func activeDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// recordDailyActivity counts today towards the user's daily streak. Days are
+// calendar days in the user's timezone: activity on the next local day extends
+// the streak, a missed day starts it over.
+func recordDailyActivity(user *models.User, now time.Time) {
+	today := localDate(now, user.Location())
+	if user.LastActiveDate == nil {
+		user.DailyStreak = 1
+		user.LastActiveDate = &today
+		return
+	}
+
+	last := activeDay(*user.LastActiveDate)
+	daysSince := int(today.Sub(last).Hours() / 24)
+	switch {
+	case daysSince == 1:
+		user.DailyStreak++
+	case daysSince > 1:
+		user.DailyStreak = 1
+	case daysSince < 0:
+		// A timezone change put today before the last active day; that day still counts
+		return
+	}
+	if user.DailyStreak == 0 {
+		user.DailyStreak = 1
+	}
+	user.LastActiveDate = &today
+}
+
+// streakBreaksAt is when the user's streak ends unless they're active again:
+// midnight at the end of the day after their last active day, in their
+// timezone, pushed back a day per streak freeze. Nil when there's no streak to lose.
+func streakBreaksAt(user *models.User, now time.Time) *time.Time {
+	if user.LastActiveDate == nil || user.DailyStreak == 0 {
+		return nil
+	}
+	// Each freeze held covers one more missed day
+	last := activeDay(*user.LastActiveDate)
+	breaks := time.Date(last.Year(), last.Month(), last.Day()+2+user.StreakFreezes, 0, 0, 0, 0, user.Location())
+	if !now.Before(breaks) {
+		return nil
+	}
+	return &breaks
+}
+
+// liveDailyStreak is the stored streak as of now: zero once it has broken
+func liveDailyStreak(user *models.User, now time.Time) int {
+	if streakBreaksAt(user, now) == nil {
+		return 0
+	}
+	return user.DailyStreak
+}
+
+// StreakFreezePrice is what a streak freeze costs in gems, and
+// StreakFreezeMax how many a user can hold; set from config in main
+var (
+	StreakFreezePrice = 50
+	StreakFreezeMax   = 2
+)
+
+const jobStreakFreezes = "streak_freezes"
+
+// RegisterStreakJobs registers the hourly streak freeze job and queues it if
+// it isn't already. It runs hourly because days end at different times in
+// different timezones.
+func RegisterStreakJobs() {
+	services.RegisterJobHandler(jobStreakFreezes, runStreakFreezes)
+
+	var queued int64
+	database.DB.Model(&models.ScheduledJob{}).Where("kind = ?", jobStreakFreezes).Count(&queued)
+	if queued == 0 {
+		if _, err := services.ScheduleJob(database.DB, jobStreakFreezes, nil, time.Now(), nil); err != nil {
+			log.Printf("Failed to queue streak freeze job: %v", err)
+		}
+	}
+}
+
+// GetStreakFreezes returns the caller's freezes, the price and their purchase
+// and usage history, newest first
+func GetStreakFreezes(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	var history []models.StreakFreezeLog
+	database.DB.Where("user_id = ?", userID).Order("created_at DESC").Limit(50).Find(&history)
+
+	return c.JSON(fiber.Map{
+		"streakFreezes": user.StreakFreezes,
+		"price":         StreakFreezePrice,
+		"max":           StreakFreezeMax,
+		"history":       history,
+	})
+}
+
+// BuyStreakFreeze spends gems on one streak freeze
+func BuyStreakFreeze(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var purchase models.GemTransaction
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Check and spend in one statement so parallel purchases can't overdraw
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND total_gems >= ? AND streak_freezes < ?", userID, StreakFreezePrice, StreakFreezeMax).
+			Updates(map[string]interface{}{
+				"total_gems":     gorm.Expr("total_gems - ?", StreakFreezePrice),
+				"streak_freezes": gorm.Expr("streak_freezes + 1"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			var user models.User
+			tx.First(&user, userID)
+			if user.StreakFreezes >= StreakFreezeMax {
+				return fiber.NewError(fiber.StatusBadRequest, "You already hold the maximum number of streak freezes")
+			}
+			return fiber.NewError(fiber.StatusBadRequest, "Not enough gems")
+		}
+
+		purchase = models.GemTransaction{
+			UserID: userID,
+			Amount: -StreakFreezePrice,
+			Reason: models.GemReasonStreakFreeze,
+		}
+		if err := tx.Create(&purchase).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.StreakFreezeLog{
+			UserID:           userID,
+			Action:           models.StreakFreezePurchased,
+			GemTransactionID: &purchase.ID,
+		}).Error
+	})
+	if err != nil {
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			return fiberErr
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to buy streak freeze",
+		})
+	}
+
+	var user models.User
+	database.DB.First(&user, userID)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"streakFreezes": user.StreakFreezes,
+		"totalGems":     user.TotalGems,
+		"transaction":   purchase,
+	})
+}
+
+// runStreakFreezes uses up freezes for everyone who missed a day and can cover
+// it, then runs again in an hour
+func runStreakFreezes(job *models.ScheduledJob) (*time.Time, error) {
+	now := time.Now()
+
+	// No timezone is a whole day ahead of UTC, so anyone who has missed a
+	// whole local day was last active before today in UTC
+	var users []models.User
+	database.DB.Where("streak_freezes > 0 AND daily_streak > 0 AND last_active_date < ?", activeDay(now)).Find(&users)
+	for i := range users {
+		applyStreakFreezes(&users[i], now)
+	}
+
+	next := now.Add(time.Hour)
+	return &next, nil
+}
+
+// applyStreakFreezes covers the days a user has missed since they were last
+// active with streak freezes, so the streak survives. Each covered day uses
+// one freeze and counts as active without adding to the streak. Nothing is
+// used unless there are enough freezes to cover every missed day.
+func applyStreakFreezes(user *models.User, now time.Time) {
+	if user.LastActiveDate == nil || user.DailyStreak == 0 || user.StreakFreezes == 0 {
+		return
+	}
+	today := localDate(now, user.Location())
+	last := activeDay(*user.LastActiveDate)
+	missed := int(today.Sub(last).Hours()/24) - 1
+	if missed < 1 || missed > user.StreakFreezes {
+		return
+	}
+	covered := last.AddDate(0, 0, missed)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// Conditional so the job and a goal completion can't both use freezes for the same days
+		result := tx.Model(&models.User{}).
+			Where("id = ? AND streak_freezes >= ? AND last_active_date < ?", user.ID, missed, covered).
+			Updates(map[string]interface{}{
+				"streak_freezes":   gorm.Expr("streak_freezes - ?", missed),
+				"last_active_date": covered,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAlreadyApplied
+		}
+		for d := 1; d <= missed; d++ {
+			day := last.AddDate(0, 0, d)
+			if err := tx.Create(&models.StreakFreezeLog{
+				UserID:      user.ID,
+				Action:      models.StreakFreezeUsed,
+				CoveredDate: &day,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	user.StreakFreezes -= missed
+	user.LastActiveDate = &covered
+
+	body := "A streak freeze kept your " + strconv.Itoa(user.DailyStreak) + "-day streak alive"
+	if missed > 1 {
+		body = strconv.Itoa(missed) + " streak freezes kept your " + strconv.Itoa(user.DailyStreak) + "-day streak alive"
+	}
+	CreateNotification(user.ID, "streak_freeze_used", "Streak freeze used", body,
+		map[string]interface{}{"streakFreezes": user.StreakFreezes},
+	)
+}
@@ -0,0 +1,310 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GetTemplates lists templates the user can use: system templates, public
+// user templates and their own. Filter with ?category=, ?gridSize= and ?mine=true.
+func GetTemplates(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	query := visibleTemplates(database.DB, userID)
+	if category := c.Query("category"); category != "" {
+		query = query.Where("category = ?", category)
+	}
+	if gridSize, err := strconv.Atoi(c.Query("gridSize")); err == nil && gridSize > 0 {
+		query = query.Where("grid_size = ?", gridSize)
+	}
+	if c.QueryBool("mine") {
+		query = query.Where("author_id = ?", userID)
+	}
+
+	var templates []models.BoardTemplate
+	if err := query.Order("is_system DESC, use_count DESC, created_at DESC").Find(&templates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to fetch templates",
+		})
+	}
+
+	if len(templates) > 0 {
+		ids := make([]uuid.UUID, len(templates))
+		for i, t := range templates {
+			ids[i] = t.ID
+		}
+
+		var counts []struct {
+			TemplateID uuid.UUID
+			Count      int
+		}
+		database.DB.Model(&models.BoardTemplateGoal{}).
+			Select("template_id, COUNT(*) AS count").
+			Where("template_id IN ?", ids).
+			Group("template_id").
+			Scan(&counts)
+
+		byID := make(map[uuid.UUID]int, len(counts))
+		for _, row := range counts {
+			byID[row.TemplateID] = row.Count
+		}
+		for i := range templates {
+			templates[i].GoalCount = byID[templates[i].ID]
+		}
+	}
+
+	return c.JSON(templates)
+}
+
+// GetTemplate returns a template with its goals and mini-goals
+func GetTemplate(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	templateID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	template, err := findVisibleTemplate(templateID, userID)
+	if err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Template not found",
+		})
+	}
+	template.GoalCount = len(template.Goals)
+
+	return c.JSON(template)
+}
+
+// PublishTemplate saves a board's squares and mini-goals as a new template.
+// Progress, images and members are not copied.
+func PublishTemplate(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, boardID, permEditBoard)
+	if err != nil {
+		return err
+	}
+
+	var req models.PublishTemplateRequest
+	c.BodyParser(&req) // optional body
+
+	if req.Title == "" {
+		req.Title = board.Title
+	}
+	if req.Category == nil {
+		req.Category = board.Category
+	}
+
+	var goals []models.Goal
+	database.DB.Where("board_id = ? AND title IS NOT NULL AND title != '' AND is_grace_square = ?", boardID, false).
+		Preload("MiniGoals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC")
+		}).
+		Order("position ASC").
+		Find(&goals)
+
+	if len(goals) == 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Add some goals to the board before publishing it as a template",
+		})
+	}
+
+	template := models.BoardTemplate{
+		AuthorID:    &userID,
+		Title:       req.Title,
+		Description: req.Description,
+		Category:    req.Category,
+		GridSize:    board.GridSize,
+		IsPublic:    req.IsPublic,
+	}
+	for _, g := range goals {
+		tg := models.BoardTemplateGoal{
+			Position:        g.Position,
+			Title:           *g.Title,
+			Description:     g.Description,
+			Icon:            g.Icon,
+			Mood:            g.Mood,
+			TargetValue:     g.TargetValue,
+			Unit:            g.Unit,
+			Recurrence:      g.Recurrence,
+			TimesPerPeriod:  g.TimesPerPeriod,
+			RequiredPeriods: g.RequiredPeriods,
+		}
+		for i, mg := range g.MiniGoals {
+			tg.MiniGoals = append(tg.MiniGoals, models.BoardTemplateMiniGoal{
+				Title:      mg.Title,
+				Percentage: mg.Percentage,
+				SortOrder:  i,
+			})
+		}
+		template.Goals = append(template.Goals, tg)
+	}
+
+	// Creates the goals and mini-goals through the associations
+	if err := database.DB.Create(&template).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to publish template",
+		})
+	}
+	template.GoalCount = len(template.Goals)
+
+	return c.Status(fiber.StatusCreated).JSON(template)
+}
+
+// DeleteTemplate removes one of the user's own templates. Boards created from it keep their goals.
+func DeleteTemplate(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	templateID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid template ID",
+		})
+	}
+
+	var template models.BoardTemplate
+	if err := database.DB.Where("id = ? AND author_id = ?", templateID, userID).First(&template).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Template not found or you are not the author",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		return hardDeleteTemplates(tx, []uuid.UUID{template.ID})
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to delete template",
+		})
+	}
+
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// visibleTemplates scopes a query to templates the user may see and use
+func visibleTemplates(db *gorm.DB, userID uuid.UUID) *gorm.DB {
+	return db.Model(&models.BoardTemplate{}).
+		Where("is_system = ? OR is_public = ? OR author_id = ?", true, true, userID)
+}
+
+// findVisibleTemplate loads a template with its goals in position order and mini-goals in sort order
+func findVisibleTemplate(templateID, userID uuid.UUID) (*models.BoardTemplate, error) {
+	var template models.BoardTemplate
+	err := visibleTemplates(database.DB, userID).
+		Where("id = ?", templateID).
+		Preload("Goals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Goals.MiniGoals", func(db *gorm.DB) *gorm.DB {
+			return db.Order("sort_order ASC")
+		}).
+		First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// applyTemplate creates the template's goals and mini-goals on a new board.
+// A square on the board's grace square moves to the first free square; if
+// there is none, or the square is outside the grid, it is skipped and listed
+// in board.SkippedTemplatePositions. Run inside a transaction.
+func applyTemplate(tx *gorm.DB, board *models.Board, template *models.BoardTemplate) error {
+	maxPosition := board.GridSize*board.GridSize - 1
+	taken := map[int]bool{}
+	if board.GraceSquarePosition != nil {
+		taken[*board.GraceSquarePosition] = true
+	}
+	for _, tg := range template.Goals {
+		taken[tg.Position] = true
+	}
+	freeSquare := func() (int, bool) {
+		for pos := 0; pos <= maxPosition; pos++ {
+			if !taken[pos] {
+				taken[pos] = true
+				return pos, true
+			}
+		}
+		return 0, false
+	}
+
+	for _, tg := range template.Goals {
+		position := tg.Position
+		if position < 0 || position > maxPosition {
+			board.SkippedTemplatePositions = append(board.SkippedTemplatePositions, tg.Position)
+			continue
+		}
+		if board.GraceSquarePosition != nil && position == *board.GraceSquarePosition {
+			free, ok := freeSquare()
+			if !ok {
+				board.SkippedTemplatePositions = append(board.SkippedTemplatePositions, tg.Position)
+				continue
+			}
+			position = free
+		}
+
+		title := tg.Title
+		goal := models.Goal{
+			BoardID:         board.ID,
+			Position:        position,
+			Title:           &title,
+			Description:     tg.Description,
+			Icon:            tg.Icon,
+			Mood:            tg.Mood,
+			TargetValue:     tg.TargetValue,
+			Unit:            tg.Unit,
+			Recurrence:      tg.Recurrence,
+			TimesPerPeriod:  tg.TimesPerPeriod,
+			RequiredPeriods: tg.RequiredPeriods,
+		}
+		if err := tx.Create(&goal).Error; err != nil {
+			return err
+		}
+
+		for _, tm := range tg.MiniGoals {
+			miniGoal := models.MiniGoal{
+				GoalID:     goal.ID,
+				Title:      tm.Title,
+				Percentage: tm.Percentage,
+			}
+			if err := tx.Create(&miniGoal).Error; err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hardDeleteTemplates permanently removes templates with their goals and mini-goals
+func hardDeleteTemplates(tx *gorm.DB, templateIDs []uuid.UUID) error {
+	if len(templateIDs) == 0 {
+		return nil
+	}
+
+	var goalIDs []uuid.UUID
+	if err := tx.Model(&models.BoardTemplateGoal{}).Where("template_id IN ?", templateIDs).Pluck("id", &goalIDs).Error; err != nil {
+		return err
+	}
+	if len(goalIDs) > 0 {
+		if err := tx.Where("template_goal_id IN ?", goalIDs).Delete(&models.BoardTemplateMiniGoal{}).Error; err != nil {
+			return err
+		}
+	}
+	if err := tx.Where("template_id IN ?", templateIDs).Delete(&models.BoardTemplateGoal{}).Error; err != nil {
+		return err
+	}
+	return tx.Unscoped().Where("id IN ?", templateIDs).Delete(&models.BoardTemplate{}).Error
+}
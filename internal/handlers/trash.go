@@ -0,0 +1,478 @@
+package handlers
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// TrashRetention is how long deleted boards, goals and mini-goals stay
+// restorable before StartTrashPurge removes them for good. Set before starting the purge.
+var TrashRetention = 30 * 24 * time.Hour
+
+// trashTimestamp is the deleted_at stamp shared by everything removed in one
+// operation. Restores bring back rows stamped at or after their parent's
+// stamp, leaving children that were deleted on their own in the trash.
+func trashTimestamp() time.Time {
+	return time.Now().Truncate(time.Microsecond)
+}
+
+// GetTrash lists the user's deleted boards, plus deleted goals and mini-goals
+// on live boards they can edit, newest first
+func GetTrash(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	items := []models.TrashItem{}
+
+	var boards []models.Board
+	database.DB.Unscoped().
+		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+		Find(&boards)
+	for _, b := range boards {
+		items = append(items, models.TrashItem{
+			Type:       models.TrashBoard,
+			ID:         b.ID,
+			Title:      b.Title,
+			BoardID:    b.ID,
+			BoardTitle: b.Title,
+			DeletedAt:  b.DeletedAt.Time,
+			PurgeAt:    b.DeletedAt.Time.Add(TrashRetention),
+		})
+	}
+
+	boardIDs := editableBoardIDs(userID)
+	if len(boardIDs) > 0 {
+		var live []models.Board
+		database.DB.Where("id IN ?", boardIDs).Select("id, title").Find(&live)
+		boardTitle := map[uuid.UUID]string{}
+		for _, b := range live {
+			boardTitle[b.ID] = b.Title
+		}
+
+		// Blank squares and grace squares aren't worth restoring
+		var goals []models.Goal
+		database.DB.Unscoped().
+			Where("board_id IN ? AND deleted_at IS NOT NULL AND title IS NOT NULL AND title != '' AND is_grace_square = ?", boardIDs, false).
+			Find(&goals)
+		for _, g := range goals {
+			position := g.Position
+			items = append(items, models.TrashItem{
+				Type:       models.TrashGoal,
+				ID:         g.ID,
+				Title:      *g.Title,
+				BoardID:    g.BoardID,
+				BoardTitle: boardTitle[g.BoardID],
+				Position:   &position,
+				DeletedAt:  g.DeletedAt.Time,
+				PurgeAt:    g.DeletedAt.Time.Add(TrashRetention),
+			})
+		}
+
+		var liveGoals []models.Goal
+		database.DB.Where("board_id IN ?", boardIDs).Select("id, board_id, title").Find(&liveGoals)
+		goalsByID := map[uuid.UUID]models.Goal{}
+		liveGoalIDs := make([]uuid.UUID, 0, len(liveGoals))
+		for _, g := range liveGoals {
+			goalsByID[g.ID] = g
+			liveGoalIDs = append(liveGoalIDs, g.ID)
+		}
+
+		if len(liveGoalIDs) > 0 {
+			var miniGoals []models.MiniGoal
+			database.DB.Unscoped().
+				Where("goal_id IN ? AND deleted_at IS NOT NULL", liveGoalIDs).
+				Find(&miniGoals)
+			for _, mg := range miniGoals {
+				goal := goalsByID[mg.GoalID]
+				goalID := mg.GoalID
+				goalTitle := ""
+				if goal.Title != nil {
+					goalTitle = *goal.Title
+				}
+				items = append(items, models.TrashItem{
+					Type:       models.TrashMiniGoal,
+					ID:         mg.ID,
+					Title:      mg.Title,
+					BoardID:    goal.BoardID,
+					BoardTitle: boardTitle[goal.BoardID],
+					GoalID:     &goalID,
+					GoalTitle:  goalTitle,
+					DeletedAt:  mg.DeletedAt.Time,
+					PurgeAt:    mg.DeletedAt.Time.Add(TrashRetention),
+				})
+			}
+		}
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].DeletedAt.After(items[j].DeletedAt)
+	})
+
+	return c.JSON(items)
+}
+
+// RestoreBoard brings back one of the user's deleted boards with the goals deleted along with it
+func RestoreBoard(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	boardID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid board ID",
+		})
+	}
+
+	var board models.Board
+	if err := database.DB.Unscoped().
+		Where("id = ? AND user_id = ? AND deleted_at IS NOT NULL", boardID, userID).
+		First(&board).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Board not found in trash",
+		})
+	}
+
+	deletedAt := board.DeletedAt.Time
+	var goalIDs []uuid.UUID
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		tx.Unscoped().Model(&models.Goal{}).
+			Where("board_id = ? AND deleted_at >= ?", board.ID, deletedAt).
+			Pluck("id", &goalIDs)
+		if err := restoreGoals(tx, goalIDs, deletedAt); err != nil {
+			return err
+		}
+
+		// Another board took over as default when this one was deleted
+		return tx.Unscoped().Model(&board).Updates(map[string]interface{}{
+			"deleted_at": nil,
+			"is_default": false,
+		}).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore board",
+		})
+	}
+	redoRestoredCompletions(&board, goalIDs)
+
+	// Reminders dropped their jobs while the goals were in the trash
+	rescheduleReminders("board_id = ?", board.ID)
+
+	database.DB.Preload("Goals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("position ASC")
+	}).Preload("Goals.MiniGoals").Preload("Members.User").First(&board, board.ID)
+
+	return c.JSON(board)
+}
+
+// RestoreGoal puts a deleted goal back in its square with its mini-goals,
+// reflection, memories and member progress. A blank square in the way is
+// replaced; a real goal in the way has to be moved or cleared first.
+func RestoreGoal(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+	goalID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid goal ID",
+		})
+	}
+
+	var goal models.Goal
+	if err := database.DB.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", goalID).
+		First(&goal).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Goal not found in trash",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, goal.BoardID, permEditGoals)
+	if err != nil {
+		return err
+	}
+
+	if goal.Position >= board.GridSize*board.GridSize {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "This goal's square is no longer on the board; resize the board first",
+		})
+	}
+
+	var occupant models.Goal
+	hasOccupant := database.DB.Where("board_id = ? AND position = ?", goal.BoardID, goal.Position).First(&occupant).Error == nil
+	if hasOccupant && (occupant.IsGraceSquare || (occupant.Title != nil && *occupant.Title != "")) {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Another goal is in this square; move or clear it first",
+		})
+	}
+
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if hasOccupant {
+			if _, err := hardDeleteGoals(tx, []uuid.UUID{occupant.ID}); err != nil {
+				return err
+			}
+		}
+		return restoreGoals(tx, []uuid.UUID{goal.ID}, goal.DeletedAt.Time)
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore goal",
+		})
+	}
+	redoRestoredCompletions(board, []uuid.UUID{goal.ID})
+
+	database.DB.Preload("MiniGoals").First(&goal, goal.ID)
+
+	rescheduleReminders("goal_id = ?", goal.ID)
+
+	LogActivity(board.ID, userID, "goal_restored", &goal.ID, map[string]interface{}{
+		"position": goal.Position,
+	})
+	if board.BoardType == "shared" {
+		WS.Broadcast(board.ID, userID, WSEvent{
+			Type:    EventGoalUpdated,
+			BoardID: board.ID.String(),
+			UserID:  userID.String(),
+			Data:    goal,
+		})
+	}
+
+	return c.JSON(goal)
+}
+
+// RestoreMiniGoal brings back a deleted mini-goal and recalculates its goal's progress
+func RestoreMiniGoal(c *fiber.Ctx) error {
+	miniGoalID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid mini-goal ID",
+		})
+	}
+
+	var miniGoal models.MiniGoal
+	if err := database.DB.Unscoped().
+		Where("id = ? AND deleted_at IS NOT NULL", miniGoalID).
+		First(&miniGoal).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "Mini-goal not found in trash",
+		})
+	}
+
+	var goal models.Goal
+	if err := database.DB.First(&goal, miniGoal.GoalID).Error; err != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Restore this mini-goal's goal first",
+		})
+	}
+
+	board, _, err := authorizeBoard(c, goal.BoardID, permEditGoals)
+	if err != nil {
+		return err
+	}
+
+	deletedAt := miniGoal.DeletedAt.Time
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.MiniGoalMember{}).
+			Where("mini_goal_id = ? AND deleted_at >= ?", miniGoal.ID, deletedAt).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Model(&miniGoal).Update("deleted_at", nil).Error
+	})
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to restore mini-goal",
+		})
+	}
+
+	refreshGoalProgress(goal.ID, board.BoardType)
+	rescheduleReminders("mini_goal_id = ?", miniGoal.ID)
+
+	return c.JSON(miniGoal)
+}
+
+// softDeleteGoals moves goals to the trash with their mini-goals, per-member
+// state, reflections and memories, all stamped with at. Run inside a transaction.
+func softDeleteGoals(tx *gorm.DB, goalIDs []uuid.UUID, at time.Time) error {
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	var miniGoalIDs []uuid.UUID
+	tx.Model(&models.MiniGoal{}).Where("goal_id IN ?", goalIDs).Pluck("id", &miniGoalIDs)
+	if err := softDeleteMiniGoals(tx, miniGoalIDs, at); err != nil {
+		return err
+	}
+
+	for _, model := range []interface{}{
+		&models.Reflection{},
+		&models.GoalMemory{},
+		&models.GoalMember{},
+		&models.GoalCheckIn{},
+		&models.GoalStreak{},
+	} {
+		if err := tx.Model(model).Where("goal_id IN ?", goalIDs).Update("deleted_at", at).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Model(&models.Goal{}).Where("id IN ?", goalIDs).Update("deleted_at", at).Error
+}
+
+// softDeleteMiniGoals moves mini-goals and their per-member state to the trash. Run inside a transaction.
+func softDeleteMiniGoals(tx *gorm.DB, miniGoalIDs []uuid.UUID, at time.Time) error {
+	if len(miniGoalIDs) == 0 {
+		return nil
+	}
+	if err := tx.Model(&models.MiniGoalMember{}).Where("mini_goal_id IN ?", miniGoalIDs).Update("deleted_at", at).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.MiniGoal{}).Where("id IN ?", miniGoalIDs).Update("deleted_at", at).Error
+}
+
+// restoreGoals undoes softDeleteGoals for goals deleted at `at`, skipping
+// children that were deleted on their own before that. Run inside a transaction.
+func restoreGoals(tx *gorm.DB, goalIDs []uuid.UUID, at time.Time) error {
+	if len(goalIDs) == 0 {
+		return nil
+	}
+
+	var miniGoalIDs []uuid.UUID
+	tx.Unscoped().Model(&models.MiniGoal{}).
+		Where("goal_id IN ? AND deleted_at >= ?", goalIDs, at).
+		Pluck("id", &miniGoalIDs)
+	if len(miniGoalIDs) > 0 {
+		if err := tx.Unscoped().Model(&models.MiniGoalMember{}).
+			Where("mini_goal_id IN ? AND deleted_at >= ?", miniGoalIDs, at).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+	}
+
+	for _, model := range []interface{}{
+		&models.MiniGoal{},
+		&models.Reflection{},
+		&models.GoalMemory{},
+		&models.GoalMember{},
+		&models.GoalCheckIn{},
+		&models.GoalStreak{},
+	} {
+		if err := tx.Unscoped().Model(model).
+			Where("goal_id IN ? AND deleted_at >= ?", goalIDs, at).
+			Update("deleted_at", nil).Error; err != nil {
+			return err
+		}
+	}
+
+	return tx.Unscoped().Model(&models.Goal{}).Where("id IN ?", goalIDs).Update("deleted_at", nil).Error
+}
+
+// editableBoardIDs returns the live boards where the user may edit goals
+func editableBoardIDs(userID uuid.UUID) []uuid.UUID {
+	var memberOf []uuid.UUID
+	var members []models.BoardMember
+	database.DB.Where("user_id = ?", userID).Find(&members)
+	for _, m := range members {
+		role := m.Role
+		if role == models.RoleOwner {
+			role = models.RoleAdmin // ownership is checked through Board.UserID below
+		}
+		if roleAllows(role, permEditGoals) {
+			memberOf = append(memberOf, m.BoardID)
+		}
+	}
+
+	// Membership rows outlive a soft-deleted board, so filter through the boards table
+	var ids []uuid.UUID
+	query := database.DB.Model(&models.Board{})
+	if len(memberOf) > 0 {
+		query = query.Where("user_id = ? OR id IN ?", userID, memberOf)
+	} else {
+		query = query.Where("user_id = ?", userID)
+	}
+	query.Pluck("id", &ids)
+	return ids
+}
+
+// StartTrashPurge permanently deletes trashed boards, goals and mini-goals
+// older than TrashRetention, once at startup and then every hour.
+func StartTrashPurge() {
+	go func() {
+		purgeTrash()
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		for range ticker.C {
+			purgeTrash()
+		}
+	}()
+}
+
+func purgeTrash() {
+	cutoff := time.Now().Add(-TrashRetention)
+	var uploads []string
+
+	var boardIDs []uuid.UUID
+	database.DB.Unscoped().Model(&models.Board{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &boardIDs)
+	for _, id := range boardIDs {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			urls, err := hardDeleteBoard(tx, id)
+			uploads = append(uploads, urls...)
+			return err
+		})
+		if err != nil {
+			log.Printf("trash: failed to purge board %s: %v", id, err)
+		}
+	}
+
+	var goalIDs []uuid.UUID
+	database.DB.Unscoped().Model(&models.Goal{}).
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Pluck("id", &goalIDs)
+	if len(goalIDs) > 0 {
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			urls, err := hardDeleteGoals(tx, goalIDs)
+			uploads = append(uploads, urls...)
+			return err
+		})
+		if err != nil {
+			log.Printf("trash: failed to purge goals: %v", err)
+		}
+	}
+
+	var miniGoals []models.MiniGoal
+	database.DB.Unscoped().
+		Where("deleted_at IS NOT NULL AND deleted_at < ?", cutoff).
+		Find(&miniGoals)
+	if len(miniGoals) > 0 {
+		ids := make([]uuid.UUID, len(miniGoals))
+		for i, mg := range miniGoals {
+			ids[i] = mg.ID
+			if mg.ImageURL != nil {
+				uploads = append(uploads, *mg.ImageURL)
+			}
+		}
+		err := database.DB.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Unscoped().Where("mini_goal_id IN ?", ids).Delete(&models.MiniGoalMember{}).Error; err != nil {
+				return err
+			}
+			if err := hardDeleteReminders(tx, "mini_goal_id IN ?", ids); err != nil {
+				return err
+			}
+			return tx.Unscoped().Where("id IN ?", ids).Delete(&models.MiniGoal{}).Error
+		})
+		if err != nil {
+			log.Printf("trash: failed to purge mini-goals: %v", err)
+		}
+	}
+
+	removeUnreferencedUploads(uploads)
+
+	if n := len(boardIDs) + len(goalIDs) + len(miniGoals); n > 0 {
+		log.Printf("trash: purged %d boards, %d goals and %d mini-goals", len(boardIDs), len(goalIDs), len(miniGoals))
+	}
+}
@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/middleware"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/arnold/bingoals-api/internal/services"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	passwordResetTTL     = 1 * time.Hour
+	emailVerificationTTL = 48 * time.Hour
+)
+
+var errTokenUsed = errors.New("token already used or superseded")
+
+// AppURL is the frontend base URL that emailed links point at; set from config in main
+var AppURL = "http://localhost:8080"
+
+// ForgotPassword emails a password reset link. Always responds the same way
+// so the endpoint can't be used to discover which emails are registered.
+func ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil || req.Email == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	var user models.User
+	if err := database.DB.Where("email = ?", req.Email).First(&user).Error; err == nil {
+		go sendPasswordResetEmail(user)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "If an account exists for that email, a reset link has been sent",
+	})
+}
+
+// ResetPassword sets a new password using a reset token and signs out every device
+func ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Token == "" || req.Password == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token and password are required",
+		})
+	}
+	if len(req.Password) < 6 {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Password must be at least 6 characters",
+		})
+	}
+
+	token, err := consumeOneTimeToken(req.Token, models.TokenPurposePasswordReset)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid or expired reset link",
+		})
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to hash password",
+		})
+	}
+
+	// Following the emailed link also proves the user owns the address
+	now := time.Now()
+	updates := map[string]interface{}{"password": string(hashedPassword)}
+	var user models.User
+	if err := database.DB.First(&user, token.UserID).Error; err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid or expired reset link",
+		})
+	}
+	if user.EmailVerifiedAt == nil {
+		updates["email_verified_at"] = now
+	}
+
+	if err := database.DB.Model(&user).Updates(updates).Error; err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": "Failed to reset password",
+		})
+	}
+
+	revokeAllSessions(user.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Password has been reset. Please log in again.",
+	})
+}
+
+// VerifyEmail marks the user's email as verified using a verification token
+func VerifyEmail(c *fiber.Ctx) error {
+	var req models.VerifyEmailRequest
+	if err := c.BodyParser(&req); err != nil || req.Token == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Token is required",
+		})
+	}
+
+	token, err := consumeOneTimeToken(req.Token, models.TokenPurposeEmailVerification)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "Invalid or expired verification link",
+		})
+	}
+
+	database.DB.Model(&models.User{}).
+		Where("id = ? AND email_verified_at IS NULL", token.UserID).
+		Update("email_verified_at", time.Now())
+
+	return c.JSON(fiber.Map{
+		"message": "Email verified",
+	})
+}
+
+// ResendVerification sends a fresh verification email to the current user
+func ResendVerification(c *fiber.Ctx) error {
+	userID := middleware.GetUserID(c)
+
+	var user models.User
+	if err := database.DB.First(&user, userID).Error; err != nil {
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": "Email is already verified",
+		})
+	}
+
+	go sendVerificationEmail(user)
+
+	return c.JSON(fiber.Map{
+		"message": "Verification email sent",
+	})
+}
+
+func sendVerificationEmail(user models.User) {
+	token, err := createOneTimeToken(user.ID, models.TokenPurposeEmailVerification, emailVerificationTTL)
+	if err != nil {
+		log.Printf("Failed to create verification token for user %s: %v", user.ID, err)
+		return
+	}
+
+	link := appURL("/verify-email", token)
+	body := "Hi " + greetingName(user) + ",\n\n" +
+		"Please confirm your email address for Bingoals by opening the link below:\n\n" +
+		link + "\n\n" +
+		"This link expires in 48 hours. If you didn't create an account, you can ignore this email."
+
+	if err := services.Mail.Send(user.Email, "Verify your Bingoals email", body); err != nil {
+		log.Printf("Failed to send verification email to user %s: %v", user.ID, err)
+	}
+}
+
+func sendPasswordResetEmail(user models.User) {
+	token, err := createOneTimeToken(user.ID, models.TokenPurposePasswordReset, passwordResetTTL)
+	if err != nil {
+		log.Printf("Failed to create reset token for user %s: %v", user.ID, err)
+		return
+	}
+
+	link := appURL("/reset-password", token)
+	body := "Hi " + greetingName(user) + ",\n\n" +
+		"Someone asked to reset the password for your Bingoals account. Open the link below to choose a new one:\n\n" +
+		link + "\n\n" +
+		"This link expires in 1 hour and can only be used once. If you didn't ask for this, you can ignore this email."
+
+	if err := services.Mail.Send(user.Email, "Reset your Bingoals password", body); err != nil {
+		log.Printf("Failed to send reset email to user %s: %v", user.ID, err)
+	}
+}
+
+// createOneTimeToken records a new token row and returns its signed form.
+// Any earlier unused token for the same purpose is superseded.
+func createOneTimeToken(userID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	now := time.Now()
+	database.DB.Model(&models.OneTimeToken{}).
+		Where("user_id = ? AND purpose = ? AND used_at IS NULL", userID, purpose).
+		Update("used_at", now)
+
+	row := models.OneTimeToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		ExpiresAt: now.Add(ttl),
+	}
+	if err := database.DB.Create(&row).Error; err != nil {
+		return "", err
+	}
+
+	return middleware.GenerateActionToken(userID, row.ID, purpose, ttl)
+}
+
+// consumeOneTimeToken verifies a signed token and atomically marks its row as used
+func consumeOneTimeToken(tokenString, purpose string) (*models.OneTimeToken, error) {
+	row, err := loadOneTimeToken(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+	if !markOneTimeTokenUsed(row) {
+		return nil, errTokenUsed
+	}
+	return row, nil
+}
+
+// loadOneTimeToken verifies a signed token and returns its row if it is still
+// redeemable, without using it up
+func loadOneTimeToken(tokenString, purpose string) (*models.OneTimeToken, error) {
+	claims, err := middleware.ParseActionToken(tokenString, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	tokenID, err := uuid.Parse(claims.ID)
+	if err != nil {
+		return nil, middleware.ErrInvalidToken
+	}
+
+	var row models.OneTimeToken
+	if err := database.DB.
+		Where("id = ? AND user_id = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", tokenID, claims.UserID, purpose, time.Now()).
+		First(&row).Error; err != nil {
+		return nil, errTokenUsed
+	}
+	return &row, nil
+}
+
+// markOneTimeTokenUsed marks the row used, reporting false if another request got there first
+func markOneTimeTokenUsed(row *models.OneTimeToken) bool {
+	now := time.Now()
+	result := database.DB.Model(&models.OneTimeToken{}).
+		Where("id = ? AND used_at IS NULL", row.ID).
+		Update("used_at", now)
+	if result.Error != nil || result.RowsAffected == 0 {
+		return false
+	}
+	row.UsedAt = &now
+	return true
+}
+
+func appURL(path, token string) string {
+	return AppURL + path + "?token=" + url.QueryEscape(token)
+}
+
+func greetingName(user models.User) string {
+	if user.DisplayName != "" {
+		return user.DisplayName
+	}
+	if user.Name != "" {
+		return user.Name
+	}
+	return "there"
+}
@@ -2,14 +2,13 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
-	"os"
 	"strings"
 	"sync"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/websocket/v2"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 
 	"github.com/arnold/bingoals-api/internal/middleware"
@@ -24,6 +23,10 @@ const (
 	EventBoardUpdated   = "board_updated"
 	EventCommentAdded   = "comment_added"
 	EventCommentDeleted = "comment_deleted"
+	EventGoalAssigned   = "goal_assigned"
+
+	EventOwnershipTransferred = "ownership_transferred"
+	EventMemberRoleChanged    = "member_role_changed"
 )
 
 // WSEvent is the JSON message sent to connected clients
@@ -104,7 +107,7 @@ func (h *Hub) Broadcast(boardID uuid.UUID, excludeUserID uuid.UUID, event WSEven
 	}
 }
 
-// WebSocketUpgrade is the middleware that checks the upgrade request and validates JWT
+// WebSocketUpgrade is the middleware that checks the upgrade request and validates the JWT and its session
 func WebSocketUpgrade() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		if !websocket.IsWebSocketUpgrade(c) {
@@ -128,24 +131,14 @@ func WebSocketUpgrade() fiber.Handler {
 			})
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key-change-in-production"
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &middleware.Claims{}, func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired token",
-			})
-		}
-
-		claims, ok := token.Claims.(*middleware.Claims)
-		if !ok {
+		claims, err := middleware.ParseToken(tokenString)
+		if err != nil {
+			message := "Invalid or expired token"
+			if errors.Is(err, middleware.ErrSessionRevoked) {
+				message = "Session has been revoked"
+			}
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token claims",
+				"error": message,
 			})
 		}
 
@@ -168,6 +161,11 @@ func HandleWebSocket(c *websocket.Conn) {
 		return
 	}
 
+	if !isBoardMember(boardID, userID) {
+		c.Close()
+		return
+	}
+
 	conn := &connection{conn: c, userID: userID}
 	WS.register(boardID, conn)
 	defer WS.unregister(boardID, conn)
@@ -1,38 +1,123 @@
 package middleware
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"time"
 
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
 	"github.com/gofiber/fiber/v2"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	ErrInvalidToken   = errors.New("invalid or expired token")
+	ErrSessionRevoked = errors.New("session has been revoked")
+)
+
 type Claims struct {
-	UserID uuid.UUID `json:"userId"`
-	Email  string    `json:"email"`
+	UserID    uuid.UUID `json:"userId"`
+	SessionID uuid.UUID `json:"sid"`
+	Email     string    `json:"email"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uuid.UUID, email string) (string, error) {
+// ActionClaims are carried by single-purpose tokens (password reset, email verification).
+// The JWT ID identifies the matching one-time token row.
+type ActionClaims struct {
+	UserID  uuid.UUID `json:"userId"`
+	Purpose string    `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+func jwtSecret() []byte {
 	secret := os.Getenv("JWT_SECRET")
 	if secret == "" {
 		secret = "your-secret-key-change-in-production"
 	}
+	return []byte(secret)
+}
 
+// GenerateToken issues a short-lived access token bound to a session
+func GenerateToken(userID, sessionID uuid.UUID, email string) (string, error) {
 	claims := Claims{
-		UserID: userID,
-		Email:  email,
+		UserID:    userID,
+		SessionID: sessionID,
+		Email:     email,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)), // 7 days
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return token.SignedString(jwtSecret())
+}
+
+// ParseToken validates an access token and checks that its session is still active
+func ParseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*Claims)
+	if !ok || claims.SessionID == uuid.Nil {
+		return nil, ErrInvalidToken
+	}
+
+	var session models.Session
+	if err := database.DB.Where("id = ? AND user_id = ?", claims.SessionID, claims.UserID).First(&session).Error; err != nil {
+		return nil, ErrSessionRevoked
+	}
+	if !session.IsActive() {
+		return nil, ErrSessionRevoked
+	}
+
+	return claims, nil
+}
+
+// GenerateActionToken signs a token that is only valid for the given purpose
+func GenerateActionToken(userID, tokenID uuid.UUID, purpose string, ttl time.Duration) (string, error) {
+	claims := ActionClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenID.String(),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(jwtSecret())
+}
+
+// ParseActionToken validates a purpose token's signature, expiry and purpose
+func ParseActionToken(tokenString, purpose string) (*ActionClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &ActionClaims{}, func(t *jwt.Token) (interface{}, error) {
+		return jwtSecret(), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}))
+	if err != nil || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	claims, ok := token.Claims.(*ActionClaims)
+	if !ok || claims.Purpose != purpose {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
 }
 
 func Protected() fiber.Handler {
@@ -52,30 +137,20 @@ func Protected() fiber.Handler {
 			})
 		}
 
-		secret := os.Getenv("JWT_SECRET")
-		if secret == "" {
-			secret = "your-secret-key-change-in-production"
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(t *jwt.Token) (interface{}, error) {
-			return []byte(secret), nil
-		})
-
-		if err != nil || !token.Valid {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid or expired token",
-			})
-		}
-
-		claims, ok := token.Claims.(*Claims)
-		if !ok {
+		claims, err := ParseToken(tokenString)
+		if err != nil {
+			message := "Invalid or expired token"
+			if errors.Is(err, ErrSessionRevoked) {
+				message = "Session has been revoked"
+			}
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "Invalid token claims",
+				"error": message,
 			})
 		}
 
 		// Store user info in context
 		c.Locals("userId", claims.UserID)
+		c.Locals("sessionId", claims.SessionID)
 		c.Locals("email", claims.Email)
 
 		return c.Next()
@@ -90,3 +165,12 @@ func GetUserID(c *fiber.Ctx) uuid.UUID {
 	}
 	return userID
 }
+
+// GetSessionID extracts the current session ID from context
+func GetSessionID(c *fiber.Ctx) uuid.UUID {
+	sessionID, ok := c.Locals("sessionId").(uuid.UUID)
+	if !ok {
+		return uuid.Nil
+	}
+	return sessionID
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/limiter"
+	"github.com/google/uuid"
+)
+
+// RateLimitStorage holds the request counters. Nil keeps them in this process's
+// memory; set it to a shared fiber.Storage (Redis, Postgres, ...) before
+// routes.Setup when running more than one instance.
+var RateLimitStorage fiber.Storage
+
+// RateLimitByIP allows max requests per window from one client IP.
+// name keeps the counters of different limiters apart in a shared store.
+func RateLimitByIP(name string, max int, window time.Duration) fiber.Handler {
+	return rateLimit(name, max, window, func(c *fiber.Ctx) string {
+		return "ip:" + c.IP()
+	})
+}
+
+// RateLimitByUser allows max requests per window for the authenticated user.
+// Must run after Protected; falls back to the client IP if there is no user.
+func RateLimitByUser(name string, max int, window time.Duration) fiber.Handler {
+	return rateLimit(name, max, window, func(c *fiber.Ctx) string {
+		if userID := GetUserID(c); userID != uuid.Nil {
+			return "user:" + userID.String()
+		}
+		return "ip:" + c.IP()
+	})
+}
+
+func rateLimit(name string, max int, window time.Duration, key func(c *fiber.Ctx) string) fiber.Handler {
+	return limiter.New(limiter.Config{
+		Max:        max,
+		Expiration: window,
+		Storage:    RateLimitStorage,
+		KeyGenerator: func(c *fiber.Ctx) string {
+			return "ratelimit:" + name + ":" + key(c)
+		},
+		// The limiter has already set Retry-After
+		LimitReached: func(c *fiber.Ctx) error {
+			return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+				"error": "Too many requests. Please try again later.",
+			})
+		},
+	})
+}
@@ -17,12 +17,23 @@ type Board struct {
 	BoardType        string         `json:"boardType" gorm:"not null;default:'personal'"` // personal, shared
 	MaxMembers       int            `json:"maxMembers" gorm:"not null;default:5"`
 	GraceSquareTitle *string        `json:"graceSquareTitle" gorm:"default:null"`
+	GraceSquarePosition *int        `json:"graceSquarePosition" gorm:"default:null"` // nil when the board has no grace square
 	IsDefault        bool           `json:"isDefault" gorm:"default:false"`
+	SourceBoardID    *uuid.UUID     `json:"sourceBoardId" gorm:"type:uuid;index"` // board this one was rolled over from
+	MilestonePatterns []string      `json:"milestonePatterns" gorm:"type:text;serializer:json"` // catalogue keys; empty means DefaultMilestonePatterns
+	CustomPatterns   []MilestonePattern `json:"customPatterns" gorm:"type:text;serializer:json"`
 	CreatedAt time.Time      `json:"createdAt"`
 	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 	Goals     []Goal         `json:"goals,omitempty" gorm:"foreignKey:BoardID"`
 	Members   []BoardMember  `json:"members,omitempty" gorm:"foreignKey:BoardID"`
+	Milestones []BoardMilestone `json:"milestones,omitempty" gorm:"foreignKey:BoardID"` // live ones, loaded by GetBoard
+
+	// Caller's role on the board, filled in by GetBoard
+	MyRole string `json:"myRole,omitempty" gorm:"-"`
+
+	// Template squares that didn't fit on a new board, filled in by CreateBoard
+	SkippedTemplatePositions []int `json:"skippedTemplatePositions,omitempty" gorm:"-"`
 }
 
 func (b *Board) BeforeCreate(tx *gorm.DB) error {
@@ -34,34 +45,81 @@ func (b *Board) BeforeCreate(tx *gorm.DB) error {
 
 // Board DTOs
 type CreateBoardRequest struct {
-	Title            string  `json:"title" validate:"required"`
-	Year             int     `json:"year"`
-	GridSize         int     `json:"gridSize"`
-	Category         *string `json:"category"`
-	BoardType        string  `json:"boardType"` // personal (default), shared
-	MaxMembers       int     `json:"maxMembers"`
-	GraceSquareTitle *string `json:"graceSquareTitle"`
+	Title               string     `json:"title" validate:"required"`
+	Year                int        `json:"year"`
+	GridSize            int        `json:"gridSize"`
+	Category            *string    `json:"category"`
+	BoardType           string     `json:"boardType"` // personal (default), shared
+	MaxMembers          int        `json:"maxMembers"`
+	GraceSquareTitle    *string    `json:"graceSquareTitle"`
+	GraceSquare         *bool      `json:"graceSquare"`         // defaults to on for 5x5 and 7x7 boards
+	GraceSquarePosition *int       `json:"graceSquarePosition"` // defaults to the centre square
+	TemplateID          *uuid.UUID `json:"templateId"`          // pre-fill goals from a BoardTemplate
+	MilestonePatterns   []string   `json:"milestonePatterns"`   // catalogue keys; defaults to DefaultMilestonePatterns
+	CustomPatterns      []MilestonePattern `json:"customPatterns"`
 }
 
 type UpdateBoardRequest struct {
-	Title     *string `json:"title"`
-	IsDefault *bool   `json:"isDefault"`
+	Title             *string             `json:"title"`
+	IsDefault         *bool               `json:"isDefault"`
+	MilestonePatterns *[]string           `json:"milestonePatterns"`
+	CustomPatterns    *[]MilestonePattern `json:"customPatterns"`
+}
+
+// Rollover modes: which goals carry over into next year's board
+const (
+	RolloverUnfinished = "unfinished" // incomplete goals, keeping their progress
+	RolloverAll        = "all"        // every goal, keeping its progress
+	RolloverTitles     = "titles"     // every goal, starting from scratch
+)
+
+type RolloverBoardRequest struct {
+	Title            string `json:"title"` // defaults to the source title with the year bumped
+	Mode             string `json:"mode"`  // unfinished (default), all, titles
+	IncludeMiniGoals bool   `json:"includeMiniGoals"`
+	IncludeMembers   bool   `json:"includeMembers"` // shared boards only
+}
+
+// Resize strategies: where each goal lands on the new grid
+const (
+	ResizeTopLeft = "top_left" // keep each goal's row and column
+	ResizeCenter  = "center"   // keep the goals centred on the grid
+	ResizeMapping = "mapping"  // explicit old position → new position
+)
+
+// Resize overflow: what happens to goals that don't fit the new grid
+const (
+	ResizeOverflowRefuse  = "refuse"  // fail with the positions that don't fit
+	ResizeOverflowArchive = "archive" // soft-delete them with their mini-goals
+)
+
+type ResizeBoardRequest struct {
+	GridSize int         `json:"gridSize" validate:"required"`
+	Strategy string      `json:"strategy"` // top_left (default), center, mapping
+	Mapping  map[int]int `json:"mapping"`  // old position → new position; unmapped goals don't fit
+	Overflow string      `json:"overflow"` // refuse (default), archive
+}
+
+type TransferOwnershipRequest struct {
+	UserID uuid.UUID `json:"userId" validate:"required"`
 }
 
 type BoardSummary struct {
-	ID                 uuid.UUID    `json:"id"`
-	Title              string       `json:"title"`
-	Year               int          `json:"year"`
-	GridSize           int          `json:"gridSize"`
-	Category           *string      `json:"category"`
-	BoardType          string       `json:"boardType"`
-	MaxMembers         int          `json:"maxMembers"`
-	IsDefault          bool         `json:"isDefault"`
-	GoalCount          int          `json:"goalCount"`
-	CompletedCount     int          `json:"completedCount"`
-	CompletedPositions []int        `json:"completedPositions"`
-	MemberCount        int          `json:"memberCount"`
-	Members            []MemberInfo `json:"members,omitempty"`
+	ID                  uuid.UUID    `json:"id"`
+	Title               string       `json:"title"`
+	Year                int          `json:"year"`
+	GridSize            int          `json:"gridSize"`
+	Category            *string      `json:"category"`
+	BoardType           string       `json:"boardType"`
+	MaxMembers          int          `json:"maxMembers"`
+	IsDefault           bool         `json:"isDefault"`
+	GraceSquarePosition *int         `json:"graceSquarePosition"`
+	GoalCount           int          `json:"goalCount"`
+	CompletedCount      int          `json:"completedCount"`
+	CompletedPositions  []int        `json:"completedPositions"`
+	MemberCount         int          `json:"memberCount"`
+	Members             []MemberInfo `json:"members,omitempty"`
+	MyRole              string       `json:"myRole"`
 }
 
 // MemberInfo is a lightweight user summary for board member lists
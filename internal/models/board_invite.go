@@ -14,6 +14,7 @@ type BoardInvite struct {
 	BoardID    uuid.UUID      `json:"boardId" gorm:"type:uuid;index;not null"`
 	InviterID  uuid.UUID      `json:"inviterId" gorm:"type:uuid;not null"`
 	InviteCode string         `json:"inviteCode" gorm:"uniqueIndex;not null"`
+	Role       string         `json:"role" gorm:"not null;default:'editor'"` // role given to people who join
 	ExpiresAt  *time.Time     `json:"expiresAt"`
 	MaxUses    int            `json:"maxUses" gorm:"default:0"` // 0 = unlimited
 	UsedCount  int            `json:"usedCount" gorm:"default:0"`
@@ -50,6 +51,7 @@ func generateInviteCode() string {
 }
 
 type CreateInviteRequest struct {
-	MaxUses   int `json:"maxUses"`   // 0 = unlimited
-	ExpiresIn int `json:"expiresIn"` // hours, 0 = never
+	MaxUses   int    `json:"maxUses"`   // 0 = unlimited
+	ExpiresIn int    `json:"expiresIn"` // hours, 0 = never
+	Role      string `json:"role"`      // admin, editor (default), viewer
 }
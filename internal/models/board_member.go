@@ -8,19 +8,53 @@ import (
 )
 
 type BoardMember struct {
-	ID       uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
-	BoardID  uuid.UUID      `json:"boardId" gorm:"type:uuid;index;not null"`
-	UserID   uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
-	Role     string         `json:"role" gorm:"not null;default:'member'"` // owner, member
-	JoinedAt time.Time      `json:"joinedAt"`
-	CreatedAt time.Time     `json:"createdAt"`
-	UpdatedAt time.Time     `json:"updatedAt"`
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	BoardID   uuid.UUID      `json:"boardId" gorm:"type:uuid;index;not null"`
+	UserID    uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
+	Role      string         `json:"role" gorm:"not null;default:'editor'"` // owner, admin, editor, viewer
+	JoinedAt  time.Time      `json:"joinedAt"`
+	CreatedAt time.Time      `json:"createdAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
 	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
 
 	// Relations (for preloading)
 	User User `json:"user,omitempty" gorm:"foreignKey:UserID"`
 }
 
+// Board roles, from most to least privileged
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+// RoleRank orders roles so permissions can be compared; unknown roles rank 0
+func RoleRank(role string) int {
+	switch role {
+	case RoleOwner:
+		return 4
+	case RoleAdmin:
+		return 3
+	case RoleEditor:
+		return 2
+	case RoleViewer:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsAssignableRole reports whether a role can be given through invites or role changes.
+// Ownership only moves through a transfer.
+func IsAssignableRole(role string) bool {
+	return role == RoleAdmin || role == RoleEditor || role == RoleViewer
+}
+
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required"` // admin, editor, viewer
+}
+
 func (bm *BoardMember) BeforeCreate(tx *gorm.DB) error {
 	if bm.ID == uuid.Nil {
 		bm.ID = uuid.New()
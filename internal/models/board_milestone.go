@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BoardMilestone records that a user reached one line of a milestone pattern
+// on a board: row 2, the diagonal, a blackout. Only one live record exists per
+// board, user, pattern and line; un-completing a goal in the line revokes it,
+// after which it can be reached again.
+type BoardMilestone struct {
+	ID        uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	BoardID   uuid.UUID  `json:"boardId" gorm:"type:uuid;not null;index;uniqueIndex:idx_board_milestone_line,where:revoked_at IS NULL"`
+	UserID    uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index;uniqueIndex:idx_board_milestone_line,where:revoked_at IS NULL"`
+	Pattern   string     `json:"pattern" gorm:"not null;uniqueIndex:idx_board_milestone_line,where:revoked_at IS NULL"`   // pattern key: row, diagonal, x, ...
+	LineIndex int        `json:"lineIndex" gorm:"not null;uniqueIndex:idx_board_milestone_line,where:revoked_at IS NULL"` // row or column for "each" patterns, otherwise 0
+	Line      string     `json:"line" gorm:"not null"`                                                                    // e.g. "row 2", "diagonal", as in the gem ledger
+	Gems      int        `json:"gems"`
+	GoalID    *uuid.UUID `json:"goalId" gorm:"type:uuid"` // the goal whose completion finished the line
+	ReachedAt time.Time  `json:"reachedAt" gorm:"not null"`
+	RevokedAt *time.Time `json:"revokedAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
+
+func (m *BoardMilestone) BeforeCreate(tx *gorm.DB) error {
+	if m.ID == uuid.Nil {
+		m.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// BoardTemplate is a reusable starting grid. System templates ship with the app
+// (AuthorID is nil); users can publish their own boards as templates.
+type BoardTemplate struct {
+	ID          uuid.UUID           `json:"id" gorm:"type:uuid;primaryKey"`
+	AuthorID    *uuid.UUID          `json:"authorId" gorm:"type:uuid;index"`
+	Title       string              `json:"title" gorm:"not null"`
+	Description *string             `json:"description"`
+	Category    *string             `json:"category" gorm:"index"`
+	GridSize    int                 `json:"gridSize" gorm:"not null;index"`
+	IsSystem    bool                `json:"isSystem" gorm:"default:false"`
+	IsPublic    bool                `json:"isPublic" gorm:"default:false"` // user templates are private unless published publicly
+	UseCount    int                 `json:"useCount" gorm:"default:0"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	UpdatedAt   time.Time           `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt      `json:"-" gorm:"index"`
+	Goals       []BoardTemplateGoal `json:"goals,omitempty" gorm:"foreignKey:TemplateID"`
+
+	// Transient field — populated when listing templates
+	GoalCount int `json:"goalCount,omitempty" gorm:"-"`
+}
+
+func (bt *BoardTemplate) BeforeCreate(tx *gorm.DB) error {
+	if bt.ID == uuid.Nil {
+		bt.ID = uuid.New()
+	}
+	return nil
+}
+
+// BoardTemplateGoal is the content of one square in a template
+type BoardTemplateGoal struct {
+	ID              uuid.UUID               `json:"id" gorm:"type:uuid;primaryKey"`
+	TemplateID      uuid.UUID               `json:"templateId" gorm:"type:uuid;index;not null"`
+	Position        int                     `json:"position" gorm:"not null"`
+	Title           string                  `json:"title" gorm:"not null"`
+	Description     *string                 `json:"description"`
+	Icon            *string                 `json:"icon"`
+	Mood            *string                 `json:"mood"`
+	TargetValue     *float64                `json:"targetValue"`
+	Unit            *string                 `json:"unit"`
+	Recurrence      *string                 `json:"recurrence"`
+	TimesPerPeriod  int                     `json:"timesPerPeriod" gorm:"default:0"`
+	RequiredPeriods *int                    `json:"requiredPeriods"`
+	CreatedAt       time.Time               `json:"createdAt"`
+	UpdatedAt       time.Time               `json:"updatedAt"`
+	MiniGoals       []BoardTemplateMiniGoal `json:"miniGoals,omitempty" gorm:"foreignKey:TemplateGoalID"`
+}
+
+func (tg *BoardTemplateGoal) BeforeCreate(tx *gorm.DB) error {
+	if tg.ID == uuid.Nil {
+		tg.ID = uuid.New()
+	}
+	return nil
+}
+
+// BoardTemplateMiniGoal is a checklist step copied onto the square's goal
+type BoardTemplateMiniGoal struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	TemplateGoalID uuid.UUID `json:"templateGoalId" gorm:"type:uuid;index;not null"`
+	Title          string    `json:"title" gorm:"not null"`
+	Percentage     *int      `json:"percentage"`
+	SortOrder      int       `json:"sortOrder" gorm:"default:0"`
+	CreatedAt      time.Time `json:"createdAt"`
+	UpdatedAt      time.Time `json:"updatedAt"`
+}
+
+func (tm *BoardTemplateMiniGoal) BeforeCreate(tx *gorm.DB) error {
+	if tm.ID == uuid.Nil {
+		tm.ID = uuid.New()
+	}
+	return nil
+}
+
+// BoardTemplate DTOs
+type PublishTemplateRequest struct {
+	Title       string  `json:"title"` // defaults to the board title
+	Description *string `json:"description"`
+	Category    *string `json:"category"` // defaults to the board category
+	IsPublic    bool    `json:"isPublic"`
+}
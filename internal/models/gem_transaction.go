@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reasons for gem transactions
+const (
+	GemReasonGoalCompleted  = "goal_completed"
+	GemReasonMilestone      = "milestone"
+	GemReasonReversal       = "reversal"
+	GemReasonOpeningBalance = "opening_balance" // balance carried over from before the ledger
+	GemReasonStreakFreeze   = "streak_freeze"   // buying a streak freeze
+)
+
+// GemTransaction is one entry in a user's gem ledger. User.TotalGems is the
+// sum of their transactions. Awards carry an idempotency key so the same goal
+// or milestone can't pay out twice; undoing a completion marks the award
+// reversed and posts a negative reversal entry, after which it can be earned again.
+type GemTransaction struct {
+	ID             uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index;uniqueIndex:idx_gem_award_key,where:reversed_at IS NULL"`
+	Amount         int        `json:"amount" gorm:"not null"`
+	Reason         string     `json:"reason" gorm:"not null"`
+	BoardID        *uuid.UUID `json:"boardId" gorm:"type:uuid;index"`
+	GoalID         *uuid.UUID `json:"goalId" gorm:"type:uuid;index"`
+	Milestone      *string    `json:"milestone"` // e.g. "row 2", "diagonal", "blackout"
+	IdempotencyKey *string    `json:"-" gorm:"uniqueIndex:idx_gem_award_key,where:reversed_at IS NULL"`
+	ReversalOf     *uuid.UUID `json:"reversalOf" gorm:"type:uuid"` // the award a reversal cancels
+	ReversedAt     *time.Time `json:"reversedAt"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+func (t *GemTransaction) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
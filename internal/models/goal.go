@@ -22,7 +22,14 @@ type Goal struct {
 	IsCompleted  bool           `json:"isCompleted" gorm:"default:false"`
 	IsGraceSquare bool          `json:"isGraceSquare" gorm:"default:false"`
 	Progress      int            `json:"progress" gorm:"default:0"`
+	TargetValue   *float64       `json:"targetValue"` // numeric goals only, e.g. 24 books
+	Unit          *string        `json:"unit"`
+	CurrentValue  float64        `json:"currentValue" gorm:"default:0"` // check-in total towards TargetValue, or periods hit for habits
+	Recurrence    *string        `json:"recurrence"` // habits only: daily, weekly, monthly
+	TimesPerPeriod int           `json:"timesPerPeriod" gorm:"default:0"` // days with a check-in needed to hit a period
+	RequiredPeriods *int         `json:"requiredPeriods"`                 // hit periods that complete the habit, e.g. 40 of 52 weeks
 	CompletedAt   *time.Time     `json:"completedAt"`
+	DueDate       *time.Time     `json:"dueDate"`
 	CreatedAt     time.Time      `json:"createdAt"`
 	UpdatedAt     time.Time      `json:"updatedAt"`
 	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
@@ -32,6 +39,9 @@ type Goal struct {
 
 	// Transient field — populated by API for shared boards, not stored in DB
 	CompletedByCount int `json:"completedByCount,omitempty" gorm:"-"`
+
+	// Transient field — the viewer's streak on a habit goal
+	Streak *GoalStreak `json:"streak,omitempty" gorm:"-"`
 }
 
 func (g *Goal) BeforeCreate(tx *gorm.DB) error {
@@ -43,11 +53,22 @@ func (g *Goal) BeforeCreate(tx *gorm.DB) error {
 
 
 type UpdateGoalRequest struct {
-	Title       *string    `json:"title"`
-	Description *string    `json:"description"`
-	Icon        *string    `json:"icon"`
-	ImageURL    *string    `json:"imageUrl"`
-	Mood        *string    `json:"mood"`
-	IsCompleted *bool      `json:"isCompleted"`
-	AssignedTo  *uuid.UUID `json:"assignedTo"`
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	Icon        *string  `json:"icon"`
+	ImageURL    *string  `json:"imageUrl"`
+	Mood        *string  `json:"mood"`
+	IsCompleted *bool    `json:"isCompleted"`
+	AssignedTo  *string  `json:"assignedTo"`  // member's user ID, "" unassigns; shared boards only
+	DueDate     *string  `json:"dueDate"`     // YYYY-MM-DD, "" clears
+	TargetValue *float64 `json:"targetValue"` // 0 turns a numeric goal back into a plain one
+	Unit        *string  `json:"unit"`
+
+	Recurrence      *string `json:"recurrence"` // daily, weekly, monthly; "" makes it a one-off goal again
+	TimesPerPeriod  *int    `json:"timesPerPeriod"`
+	RequiredPeriods *int    `json:"requiredPeriods"`
+}
+
+type MoveGoalRequest struct {
+	TargetPosition *int `json:"targetPosition" validate:"required"`
 }
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// GoalCheckIn logs an increment towards a goal's target value ("read 2 books",
+// "ran 5.5 km"). On shared boards each member's check-ins count only for them.
+type GoalCheckIn struct {
+	ID          uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	GoalID      uuid.UUID      `json:"goalId" gorm:"type:uuid;index;not null"`
+	UserID      uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
+	Value       float64        `json:"value" gorm:"not null"` // negative values correct earlier check-ins
+	Note        *string        `json:"note"`
+	CheckedInAt time.Time      `json:"checkedInAt" gorm:"index;not null"`
+	CreatedAt   time.Time      `json:"createdAt"`
+	UpdatedAt   time.Time      `json:"updatedAt"`
+	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (ci *GoalCheckIn) BeforeCreate(tx *gorm.DB) error {
+	if ci.ID == uuid.Nil {
+		ci.ID = uuid.New()
+	}
+	return nil
+}
+
+type CreateCheckInRequest struct {
+	Value       float64    `json:"value" validate:"required"`
+	Note        *string    `json:"note"`
+	CheckedInAt *time.Time `json:"checkedInAt"` // defaults to now
+}
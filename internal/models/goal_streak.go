@@ -0,0 +1,37 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Recurrence periods for habit goals
+const (
+	RecurrenceDaily   = "daily"
+	RecurrenceWeekly  = "weekly"
+	RecurrenceMonthly = "monthly"
+)
+
+// GoalStreak is one user's run of hit periods on a habit goal, recomputed from
+// their check-ins. Periods follow the user's timezone; weeks start on Monday.
+type GoalStreak struct {
+	ID            uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	GoalID        uuid.UUID      `json:"goalId" gorm:"type:uuid;not null;uniqueIndex:idx_streak_goal_user"`
+	UserID        uuid.UUID      `json:"userId" gorm:"type:uuid;not null;uniqueIndex:idx_streak_goal_user"`
+	CurrentStreak int            `json:"currentStreak" gorm:"default:0"`
+	LongestStreak int            `json:"longestStreak" gorm:"default:0"`
+	PeriodsHit    int            `json:"periodsHit" gorm:"default:0"`
+	LastHitPeriod *time.Time     `json:"lastHitPeriod"` // start date of the latest hit period
+	CreatedAt     time.Time      `json:"createdAt"`
+	UpdatedAt     time.Time      `json:"updatedAt"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (s *GoalStreak) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
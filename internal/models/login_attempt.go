@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoginAttempt is an audit record of a password login, used for lockout.
+// Keyed by the (lower-cased) email that was tried, which may not be a real account.
+type LoginAttempt struct {
+	ID        uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	Email     string    `json:"email" gorm:"index:idx_login_attempt_email_time;not null"`
+	IPAddress string    `json:"ipAddress"`
+	UserAgent string    `json:"userAgent"`
+	Success   bool      `json:"success"`
+	CreatedAt time.Time `json:"createdAt" gorm:"index:idx_login_attempt_email_time"`
+}
+
+func (la *LoginAttempt) BeforeCreate(tx *gorm.DB) error {
+	if la.ID == uuid.Nil {
+		la.ID = uuid.New()
+	}
+	return nil
+}
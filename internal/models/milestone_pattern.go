@@ -0,0 +1,63 @@
+package models
+
+// Shapes a milestone pattern is built from
+const (
+	ShapeRow          = "row"
+	ShapeColumn       = "column"
+	ShapeDiagonal     = "diagonal"      // top-left to bottom-right
+	ShapeAntiDiagonal = "anti-diagonal" // top-right to bottom-left
+	ShapeCorners      = "corners"
+	ShapeAll          = "all"
+)
+
+// Which row or column a row or column part covers
+const (
+	PartFirst  = "first"
+	PartMiddle = "middle"
+	PartLast   = "last"
+	PartEach   = "each" // any one of them: the pattern can be reached once per row or column
+)
+
+// PatternPart is one piece of a pattern's shape. Index applies to rows and columns.
+type PatternPart struct {
+	Shape string `json:"shape"`
+	Index string `json:"index,omitempty"`
+}
+
+// MilestonePattern is a set of squares that pays out gems once every one of
+// them is complete. Catalogue patterns are built from shape parts so they fit
+// any grid size; a board's custom patterns list their positions directly.
+type MilestonePattern struct {
+	Key         string        `json:"key"`
+	Name        string        `json:"name"`
+	Gems        int           `json:"gems"`
+	Parts       []PatternPart `json:"parts,omitempty"`
+	Positions   []int         `json:"positions,omitempty"`
+	MinGridSize int           `json:"minGridSize,omitempty"`
+}
+
+// MilestonePatternCatalogue lists the patterns boards can choose from
+var MilestonePatternCatalogue = []MilestonePattern{
+	{Key: "row", Name: "Row", Gems: 10, Parts: []PatternPart{{ShapeRow, PartEach}}},
+	{Key: "column", Name: "Column", Gems: 10, Parts: []PatternPart{{ShapeColumn, PartEach}}},
+	{Key: "diagonal", Name: "Diagonal", Gems: 10, Parts: []PatternPart{{ShapeDiagonal, ""}}},
+	{Key: "anti-diagonal", Name: "Anti-diagonal", Gems: 10, Parts: []PatternPart{{ShapeAntiDiagonal, ""}}},
+	{Key: "corners", Name: "Four corners", Gems: 15, Parts: []PatternPart{{ShapeCorners, ""}}},
+	{Key: "blackout", Name: "Blackout", Gems: 50, Parts: []PatternPart{{ShapeAll, ""}}},
+	{Key: "x", Name: "X", Gems: 25, Parts: []PatternPart{{ShapeDiagonal, ""}, {ShapeAntiDiagonal, ""}}},
+	{Key: "plus", Name: "Plus", Gems: 25, Parts: []PatternPart{{ShapeRow, PartMiddle}, {ShapeColumn, PartMiddle}}},
+	{Key: "t", Name: "T", Gems: 20, Parts: []PatternPart{{ShapeRow, PartFirst}, {ShapeColumn, PartMiddle}}},
+	{Key: "frame", Name: "Outer frame", Gems: 30, MinGridSize: 5, Parts: []PatternPart{
+		{ShapeRow, PartFirst}, {ShapeRow, PartLast}, {ShapeColumn, PartFirst}, {ShapeColumn, PartLast},
+	}},
+}
+
+// DefaultMilestonePatterns are used by boards that haven't chosen their own
+var DefaultMilestonePatterns = []string{"row", "column", "diagonal", "anti-diagonal", "corners", "blackout"}
+
+// CompletionGems is the base award for completing a goal, by grid size;
+// smaller boards pay more per square
+var CompletionGems = map[int]int{3: 5, 5: 3, 7: 2}
+
+// MaxCustomPatterns caps how many custom patterns a board can have
+const MaxCustomPatterns = 10
@@ -14,6 +14,7 @@ type MiniGoal struct {
 	Percentage *int           `json:"percentage"`
 	IsComplete bool           `json:"isComplete" gorm:"default:false"`
 	ImageURL   *string        `json:"imageUrl"`
+	DueDate    *time.Time     `json:"dueDate"`
 	CreatedAt  time.Time      `json:"createdAt"`
 	UpdatedAt  time.Time      `json:"updatedAt"`
 	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
@@ -28,12 +29,14 @@ func (m *MiniGoal) BeforeCreate(tx *gorm.DB) error {
 
 // MiniGoal DTOs
 type CreateMiniGoalRequest struct {
-	Title      string `json:"title" validate:"required"`
-	Percentage *int   `json:"percentage"`
+	Title      string  `json:"title" validate:"required"`
+	Percentage *int    `json:"percentage"`
+	DueDate    *string `json:"dueDate"` // YYYY-MM-DD
 }
 
 type UpdateMiniGoalRequest struct {
 	Title      *string `json:"title"`
 	Percentage *int    `json:"percentage"`
 	ImageURL   *string `json:"imageUrl"`
+	DueDate    *string `json:"dueDate"` // YYYY-MM-DD, "" clears
 }
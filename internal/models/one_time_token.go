@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Token purposes
+const (
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposeMFAPending        = "mfa_pending"
+)
+
+// OneTimeToken records a signed single-use token so it can be redeemed at most once.
+// The token itself is a JWT whose jti is this row's ID.
+type OneTimeToken struct {
+	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
+	Purpose        string         `json:"purpose" gorm:"not null"` // password_reset, email_verification, mfa_pending
+	ExpiresAt      time.Time      `json:"expiresAt" gorm:"not null"`
+	UsedAt         *time.Time     `json:"usedAt"`
+	FailedAttempts int            `json:"failedAttempts" gorm:"default:0"`
+	CreatedAt      time.Time      `json:"createdAt"`
+	UpdatedAt      time.Time      `json:"updatedAt"`
+	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (t *OneTimeToken) BeforeCreate(tx *gorm.DB) error {
+	if t.ID == uuid.Nil {
+		t.ID = uuid.New()
+	}
+	return nil
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=6"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Reminder kinds
+const (
+	ReminderBeforeDue = "before_due"
+	ReminderDaily     = "daily"
+	ReminderWeekly    = "weekly"
+)
+
+// ReminderRule reminds one user about a goal or one of its mini-goals.
+// Times are wall-clock times in the user's timezone.
+type ReminderRule struct {
+	ID         uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID     uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
+	BoardID    uuid.UUID      `json:"boardId" gorm:"type:uuid;index;not null"`
+	GoalID     uuid.UUID      `json:"goalId" gorm:"type:uuid;index;not null"`
+	MiniGoalID *uuid.UUID     `json:"miniGoalId" gorm:"type:uuid;index"` // nil reminds about the goal itself
+	Kind       string         `json:"kind" gorm:"not null"`              // before_due, daily, weekly
+	DaysBefore int            `json:"daysBefore" gorm:"default:0"`       // before_due only, 0 is the due date itself
+	Weekday    int            `json:"weekday" gorm:"default:1"`          // weekly only, 0 is Sunday
+	TimeOfDay  string         `json:"timeOfDay" gorm:"not null"`         // HH:MM
+	NextRunAt  *time.Time     `json:"nextRunAt" gorm:"-"`
+	CreatedAt  time.Time      `json:"createdAt"`
+	UpdatedAt  time.Time      `json:"updatedAt"`
+	DeletedAt  gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (r *ReminderRule) BeforeCreate(tx *gorm.DB) error {
+	if r.ID == uuid.Nil {
+		r.ID = uuid.New()
+	}
+	return nil
+}
+
+type CreateReminderRequest struct {
+	MiniGoalID *uuid.UUID `json:"miniGoalId"`
+	Kind       string     `json:"kind" validate:"required"`
+	DaysBefore int        `json:"daysBefore"`
+	Weekday    *int       `json:"weekday"`
+	TimeOfDay  string     `json:"timeOfDay"` // defaults to 09:00
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ScheduledJob is a unit of deferred work run by the in-process scheduler.
+// Jobs live in the database so they survive restarts; a job is deleted once
+// its handler succeeds unless the handler asks for another run.
+type ScheduledJob struct {
+	ID          uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	Kind        string     `json:"kind" gorm:"index;not null"`   // selects the registered handler
+	RefID       *uuid.UUID `json:"refId" gorm:"type:uuid;index"` // the row the job is about, e.g. a reminder rule
+	Payload     *string    `json:"payload"`                      // JSON string
+	RunAt       time.Time  `json:"runAt" gorm:"index;not null"`
+	Attempts    int        `json:"attempts" gorm:"default:0"`
+	LastError   *string    `json:"lastError"`
+	LockedUntil *time.Time `json:"lockedUntil"` // claimed by a worker until then
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+func (j *ScheduledJob) BeforeCreate(tx *gorm.DB) error {
+	if j.ID == uuid.Nil {
+		j.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Session is a server-side login session backing a rotating refresh token.
+// Access tokens carry the session ID so a revoked session invalidates them immediately.
+type Session struct {
+	ID                uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID            uuid.UUID      `json:"userId" gorm:"type:uuid;index;not null"`
+	RefreshTokenHash  string         `json:"-" gorm:"uniqueIndex;not null"`
+	PreviousTokenHash *string        `json:"-" gorm:"index"` // last rotated-out token, used to detect reuse
+	UserAgent         string         `json:"userAgent"`
+	IPAddress         string         `json:"ipAddress"`
+	ExpiresAt         time.Time      `json:"expiresAt" gorm:"not null"`
+	LastUsedAt        time.Time      `json:"lastUsedAt"`
+	RevokedAt         *time.Time     `json:"revokedAt"`
+	CreatedAt         time.Time      `json:"createdAt"`
+	UpdatedAt         time.Time      `json:"updatedAt"`
+	DeletedAt         gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (s *Session) BeforeCreate(tx *gorm.DB) error {
+	if s.ID == uuid.Nil {
+		s.ID = uuid.New()
+	}
+	return nil
+}
+
+// IsActive checks if the session can still be used
+func (s *Session) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
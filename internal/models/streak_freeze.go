@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Streak freeze log actions
+const (
+	StreakFreezePurchased = "purchased"
+	StreakFreezeUsed      = "used"
+)
+
+// StreakFreezeLog records a streak freeze being bought with gems or used up
+// to cover a missed day
+type StreakFreezeLog struct {
+	ID               uuid.UUID  `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID           uuid.UUID  `json:"userId" gorm:"type:uuid;not null;index"`
+	Action           string     `json:"action" gorm:"not null"`
+	GemTransactionID *uuid.UUID `json:"gemTransactionId" gorm:"type:uuid"` // the purchase
+	CoveredDate      *time.Time `json:"coveredDate"`                       // the missed day a used freeze covered
+	CreatedAt        time.Time  `json:"createdAt"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+func (l *StreakFreezeLog) BeforeCreate(tx *gorm.DB) error {
+	if l.ID == uuid.Nil {
+		l.ID = uuid.New()
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Trash item types
+const (
+	TrashBoard    = "board"
+	TrashGoal     = "goal"
+	TrashMiniGoal = "mini_goal"
+)
+
+// TrashItem is a soft-deleted board, goal or mini-goal that can still be restored
+type TrashItem struct {
+	Type       string     `json:"type"` // board, goal, mini_goal
+	ID         uuid.UUID  `json:"id"`
+	Title      string     `json:"title"`
+	BoardID    uuid.UUID  `json:"boardId"`
+	BoardTitle string     `json:"boardTitle"`
+	GoalID     *uuid.UUID `json:"goalId,omitempty"`    // mini-goals only
+	GoalTitle  string     `json:"goalTitle,omitempty"` // mini-goals only
+	Position   *int       `json:"position,omitempty"`  // goals only
+	DeletedAt  time.Time  `json:"deletedAt"`
+	PurgeAt    time.Time  `json:"purgeAt"`
+}
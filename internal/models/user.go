@@ -8,25 +8,29 @@ import (
 )
 
 type User struct {
-	ID             uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
-	Email          string         `json:"email" gorm:"uniqueIndex;not null"`
-	Password       string         `json:"-"`
-	AuthProvider   string         `json:"authProvider" gorm:"default:email"`
-	Name           string         `json:"name"`
-	DisplayName    string         `json:"displayName"`
-	AvatarURL      string         `json:"avatarUrl"`
-	Bio            string         `json:"bio"`
-	DailyStreak    int            `json:"dailyStreak" gorm:"default:0"`
-	TotalGems      int            `json:"totalGems" gorm:"default:0"`
-	LastActiveDate *time.Time     `json:"lastActiveDate"`
-	FCMToken       string         `json:"-" gorm:"column:fcm_token"`
-	CreatedAt      time.Time      `json:"createdAt"`
-	UpdatedAt      time.Time      `json:"updatedAt"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
-	Boards         []Board        `json:"boards,omitempty" gorm:"foreignKey:UserID"`
+	ID              uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	Email           string         `json:"email" gorm:"uniqueIndex;not null"`
+	EmailVerifiedAt *time.Time     `json:"emailVerifiedAt"`
+	Password        string         `json:"-"`
+	Name            string         `json:"name"`
+	DisplayName     string         `json:"displayName"`
+	AvatarURL       string         `json:"avatarUrl"`
+	Bio             string         `json:"bio"`
+	DailyStreak     int            `json:"dailyStreak" gorm:"default:0"`
+	TotalGems       int            `json:"totalGems" gorm:"default:0"`
+	LastActiveDate  *time.Time     `json:"lastActiveDate"`
+	StreakFreezes   int            `json:"streakFreezes" gorm:"default:0"`
+	Timezone        string         `json:"timezone" gorm:"not null;default:'UTC'"` // IANA name, e.g. "Europe/Berlin"
+	FCMToken        string         `json:"-" gorm:"column:fcm_token"`
+	TOTPSecret      string         `json:"-" gorm:"column:totp_secret"`
+	TOTPEnabledAt   *time.Time     `json:"-" gorm:"column:totp_enabled_at"`
+	TOTPLastStep    int64          `json:"-" gorm:"column:totp_last_step;default:0"` // last accepted time step, blocks replay
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+	Boards          []Board        `json:"boards,omitempty" gorm:"foreignKey:UserID"`
 }
 
-
 func (u *User) Level() string {
 	switch {
 	case u.TotalGems >= 2000:
@@ -40,6 +44,15 @@ func (u *User) Level() string {
 	}
 }
 
+// Location is the user's timezone, falling back to UTC if it doesn't load
+func (u *User) Location() *time.Location {
+	loc, err := time.LoadLocation(u.Timezone)
+	if err != nil || u.Timezone == "" {
+		return time.UTC
+	}
+	return loc
+}
+
 func (u *User) BeforeCreate(tx *gorm.DB) error {
 	if u.ID == uuid.Nil {
 		u.ID = uuid.New()
@@ -68,9 +81,22 @@ type UpdateProfileRequest struct {
 	AvatarURL   *string `json:"avatarUrl"`
 	Bio         *string `json:"bio"`
 	Name        *string `json:"name"`
+	Timezone    *string `json:"timezone"`
+}
+
+type DeleteAccountRequest struct {
+	Password string `json:"password"` // required when the account has a password
+	Confirm  string `json:"confirm"`  // must be "DELETE"
+}
+
+// MFAEnabled reports whether TOTP two-factor authentication is switched on
+func (u *User) MFAEnabled() bool {
+	return u.TOTPEnabledAt != nil
 }
 
 type AuthResponse struct {
-	Token string `json:"token"`
-	User  User   `json:"user"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // access token lifetime in seconds
+	User         User   `json:"user"`
 }
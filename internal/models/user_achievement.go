@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UserAchievement records that a user earned an achievement from the
+// catalogue. Achievements are kept once earned.
+type UserAchievement struct {
+	ID             uuid.UUID `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID `json:"userId" gorm:"type:uuid;not null;uniqueIndex:idx_user_achievement"`
+	AchievementKey string    `json:"key" gorm:"not null;uniqueIndex:idx_user_achievement"`
+	CreatedAt      time.Time `json:"awardedAt"`
+	UpdatedAt      time.Time `json:"-"`
+}
+
+func (a *UserAchievement) BeforeCreate(tx *gorm.DB) error {
+	if a.ID == uuid.Nil {
+		a.ID = uuid.New()
+	}
+	return nil
+}
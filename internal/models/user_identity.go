@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Identity providers
+const (
+	ProviderEmail  = "email" // password login; not stored as an identity row
+	ProviderGoogle = "google"
+)
+
+// LegacySubjectPrefix marks identities backfilled from the old
+// users.auth_provider column, which never recorded the provider's subject.
+// The next sign-in through that provider fills in the real one.
+const LegacySubjectPrefix = "legacy:"
+
+// UserIdentity links an external sign-in (provider + subject) to a user.
+type UserIdentity struct {
+	ID        uuid.UUID      `json:"id" gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID      `json:"userId" gorm:"type:uuid;not null;uniqueIndex:idx_identity_user_provider"`
+	Provider  string         `json:"provider" gorm:"not null;uniqueIndex:idx_identity_provider_subject;uniqueIndex:idx_identity_user_provider"`
+	Subject   string         `json:"-" gorm:"not null;uniqueIndex:idx_identity_provider_subject"`
+	Email     string         `json:"email"`
+	CreatedAt time.Time      `json:"linkedAt"`
+	UpdatedAt time.Time      `json:"updatedAt"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+func (ui *UserIdentity) BeforeCreate(tx *gorm.DB) error {
+	if ui.ID == uuid.Nil {
+		ui.ID = uuid.New()
+	}
+	return nil
+}
+
+type LinkIdentityRequest struct {
+	Provider string `json:"provider" validate:"required"` // google
+	IDToken  string `json:"idToken" validate:"required"`
+}
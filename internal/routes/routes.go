@@ -1,6 +1,8 @@
 package routes
 
 import (
+	"time"
+
 	"github.com/arnold/bingoals-api/internal/handlers"
 	"github.com/arnold/bingoals-api/internal/middleware"
 	"github.com/gofiber/fiber/v2"
@@ -10,15 +12,37 @@ import (
 func Setup(app *fiber.App) {
 	api := app.Group("/api")
 
+	// Per-IP limits on the unauthenticated endpoints attackers can hammer
 	auth := api.Group("/auth")
-	auth.Post("/register", handlers.Register)
-	auth.Post("/login", handlers.Login)
-	auth.Post("/google", handlers.GoogleLogin)
+	auth.Post("/register", middleware.RateLimitByIP("register", 10, time.Hour), handlers.Register)
+	auth.Post("/login", middleware.RateLimitByIP("login", 10, time.Minute), handlers.Login)
+	auth.Post("/google", middleware.RateLimitByIP("google", 20, time.Minute), handlers.GoogleLogin)
+	auth.Post("/refresh", handlers.RefreshToken)
+	auth.Post("/logout", middleware.Protected(), handlers.Logout)
+	auth.Post("/logout-all", middleware.Protected(), handlers.LogoutAll)
+	auth.Post("/password/forgot", middleware.RateLimitByIP("password-forgot", 5, 15*time.Minute), handlers.ForgotPassword)
+	auth.Post("/password/reset", middleware.RateLimitByIP("password-reset", 10, 15*time.Minute), handlers.ResetPassword)
+	auth.Post("/email/verify", handlers.VerifyEmail)
+	auth.Post("/mfa/verify", middleware.RateLimitByIP("mfa", 10, time.Minute), handlers.VerifyMFA)
 
 	protected := api.Group("/", middleware.Protected())
 
 	protected.Get("/me", handlers.GetMe)
 	protected.Put("/me", handlers.UpdateProfile)
+	protected.Delete("/me", handlers.DeleteAccount)
+	protected.Get("/me/export", handlers.ExportAccount)
+	protected.Get("/me/assigned-goals", handlers.GetAssignedGoals)
+	protected.Get("/me/gems", handlers.GetGems)
+	protected.Get("/me/streak-freezes", handlers.GetStreakFreezes)
+	protected.Post("/me/streak-freezes", handlers.BuyStreakFreeze)
+	protected.Post("/me/email/verification", handlers.ResendVerification)
+	protected.Get("/me/identities", handlers.ListIdentities)
+	protected.Post("/me/identities", handlers.LinkIdentity)
+	protected.Delete("/me/identities/:provider", handlers.UnlinkIdentity)
+	protected.Post("/me/mfa/totp/enroll", handlers.EnrollTOTP)
+	protected.Post("/me/mfa/totp/confirm", handlers.ConfirmTOTP)
+	protected.Delete("/me/mfa/totp", handlers.DisableTOTP)
+	protected.Post("/me/mfa/recovery-codes", handlers.RegenerateRecoveryCodes)
 	protected.Get("/users/:id", handlers.GetUserProfile)
 
 	boards := protected.Group("/boards")
@@ -30,6 +54,7 @@ func Setup(app *fiber.App) {
 
 	boards.Put("/:boardId/goals/:position", handlers.UpdateGoal)
 	boards.Post("/:boardId/goals/:position/toggle", handlers.ToggleGoalCompletion)
+	boards.Post("/:boardId/goals/:position/move", handlers.MoveGoal)
 
 	boards.Post("/:boardId/goals/:position/mini-goals", handlers.CreateMiniGoal)
 	boards.Post("/:boardId/goals/:position/mini-goals/:miniGoalId/toggle", handlers.ToggleMiniGoal)
@@ -44,17 +69,54 @@ func Setup(app *fiber.App) {
 	boards.Patch("/:boardId/goals/:position/memories/:memoryId", handlers.UpdateGoalMemory)
 	boards.Delete("/:boardId/goals/:position/memories/:memoryId", handlers.DeleteGoalMemory)
 
+	// Check-ins towards a goal's target value
+	boards.Get("/:boardId/goals/:position/check-ins", handlers.GetCheckIns)
+	boards.Post("/:boardId/goals/:position/check-ins", handlers.CreateCheckIn)
+	boards.Delete("/:boardId/goals/:position/check-ins/:checkInId", handlers.DeleteCheckIn)
+
+	// Personal reminders for a goal and its mini-goals
+	boards.Get("/:boardId/goals/:position/reminders", handlers.GetReminders)
+	boards.Post("/:boardId/goals/:position/reminders", handlers.CreateReminder)
+
 	// Board invites & members
 	boards.Post("/:id/invites", handlers.CreateInvite)
 	boards.Get("/:id/members", handlers.GetMembers)
 	boards.Delete("/:id/members/:userId", handlers.RemoveMember)
+	boards.Put("/:id/members/:userId/role", handlers.UpdateMemberRole)
 	boards.Post("/:id/leave", handlers.LeaveBoard)
+	boards.Post("/:id/transfer", handlers.TransferOwnership)
+
+	// Change the grid size, moving goals to new positions
+	boards.Post("/:id/resize", handlers.ResizeBoard)
+
+	// Start next year's board from this one
+	boards.Post("/:id/rollover", handlers.RolloverBoard)
+
+	// Publish a board as a template
+	boards.Post("/:id/template", handlers.PublishTemplate)
 
 	// Board activity
 	boards.Get("/:id/activity", handlers.GetBoardActivity)
 
+	// Board templates
+	templates := protected.Group("/templates")
+	templates.Get("/", handlers.GetTemplates)
+	templates.Get("/:id", handlers.GetTemplate)
+	templates.Delete("/:id", handlers.DeleteTemplate)
+
+	// Milestone patterns boards can choose from
+	protected.Get("/milestone-patterns", handlers.GetMilestonePatterns)
+
+	// Deleted boards, goals and mini-goals
+	trash := protected.Group("/trash")
+	trash.Get("/", handlers.GetTrash)
+	trash.Post("/boards/:id/restore", handlers.RestoreBoard)
+	trash.Post("/goals/:id/restore", handlers.RestoreGoal)
+	trash.Post("/mini-goals/:id/restore", handlers.RestoreMiniGoal)
+
 	// Join board via invite code
-	protected.Post("/invites/:code/join", handlers.JoinBoard)
+	// Limited per user so invite codes can't be brute-forced
+	protected.Post("/invites/:code/join", middleware.RateLimitByUser("join", 10, time.Minute), handlers.JoinBoard)
 
 	// Goal reactions
 	goals := protected.Group("/goals")
@@ -70,11 +132,14 @@ func Setup(app *fiber.App) {
 	notifications.Put("/:id/read", handlers.MarkNotificationRead)
 	notifications.Post("/read-all", handlers.MarkAllRead)
 
+	// Reminders
+	protected.Delete("/reminders/:id", handlers.DeleteReminder)
+
 	// Device token for push notifications
 	protected.Post("/device-token", handlers.RegisterDeviceToken)
 
 	// File upload
-	protected.Post("/upload", handlers.UploadImage)
+	protected.Post("/upload", middleware.RateLimitByUser("upload", 30, time.Minute), handlers.UploadImage)
 
 	// Vision Gallery — all milestones across user's boards
 	protected.Get("/gallery", handlers.GetGallery)
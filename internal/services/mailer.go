@@ -0,0 +1,109 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/config"
+)
+
+// Mailer sends plain-text transactional email (verification links, password resets).
+type Mailer interface {
+	Send(to, subject, body string) error
+}
+
+// Global mailer instance
+var Mail Mailer
+
+// InitMailer picks the SMTP mailer when SMTP_HOST is configured, otherwise
+// falls back to the log mailer so dev and test environments never send real mail.
+func InitMailer(cfg *config.Config) {
+	if cfg.SMTPHost == "" {
+		Mail = &LogMailer{Path: cfg.MailLogFile}
+		log.Println("Mail: No SMTP host configured, emails will be logged")
+		return
+	}
+
+	Mail = &SMTPMailer{
+		Host:     cfg.SMTPHost,
+		Port:     cfg.SMTPPort,
+		Username: cfg.SMTPUsername,
+		Password: cfg.SMTPPassword,
+		From:     cfg.MailFrom,
+	}
+	log.Printf("Mail: Sending via SMTP %s:%s", cfg.SMTPHost, cfg.SMTPPort)
+}
+
+// SMTPMailer delivers mail through an SMTP relay (STARTTLS when the server offers it).
+type SMTPMailer struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (m *SMTPMailer) Send(to, subject, body string) error {
+	var auth smtp.Auth
+	if m.Username != "" {
+		auth = smtp.PlainAuth("", m.Username, m.Password, m.Host)
+	}
+
+	msg := strings.Join([]string{
+		"From: " + m.From,
+		"To: " + to,
+		"Subject: " + subject,
+		"Date: " + time.Now().Format(time.RFC1123Z),
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=UTF-8",
+		"",
+		body,
+	}, "\r\n")
+
+	if err := smtp.SendMail(m.Host+":"+m.Port, auth, envelopeAddress(m.From), []string{to}, []byte(msg)); err != nil {
+		return fmt.Errorf("smtp send to %s: %w", to, err)
+	}
+	return nil
+}
+
+// LogMailer writes messages to the server log, or appends them to Path when set.
+type LogMailer struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (m *LogMailer) Send(to, subject, body string) error {
+	entry := fmt.Sprintf("--- %s\nTo: %s\nSubject: %s\n\n%s\n", time.Now().Format(time.RFC3339), to, subject, body)
+
+	if m.Path == "" {
+		log.Printf("Mail (not sent):\n%s", entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("open mail log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
+
+// envelopeAddress extracts the bare address from "Name <addr>" for the SMTP envelope
+func envelopeAddress(from string) string {
+	if start := strings.Index(from, "<"); start >= 0 {
+		if end := strings.Index(from[start:], ">"); end > 0 {
+			return from[start+1 : start+end]
+		}
+	}
+	return from
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/arnold/bingoals-api/internal/database"
+	"github.com/arnold/bingoals-api/internal/models"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// JobHandler runs one scheduled job. Returning a non-nil time reschedules the
+// job to run again then; otherwise the job is deleted once it succeeds.
+type JobHandler func(job *models.ScheduledJob) (*time.Time, error)
+
+const (
+	jobBatchSize   = 50
+	jobLease       = 5 * time.Minute // a crashed worker's claim expires after this
+	jobMaxAttempts = 5
+)
+
+var (
+	jobHandlersMu sync.RWMutex
+	jobHandlers   = map[string]JobHandler{}
+)
+
+// RegisterJobHandler sets the handler for a job kind. Register handlers before
+// StartScheduler so jobs left over from the last run find theirs.
+func RegisterJobHandler(kind string, handler JobHandler) {
+	jobHandlersMu.Lock()
+	defer jobHandlersMu.Unlock()
+	jobHandlers[kind] = handler
+}
+
+// ScheduleJob queues a job of the given kind to run at runAt. Pass tx to
+// queue it inside a caller's transaction.
+func ScheduleJob(tx *gorm.DB, kind string, refID *uuid.UUID, runAt time.Time, payload interface{}) (*models.ScheduledJob, error) {
+	job := models.ScheduledJob{Kind: kind, RefID: refID, RunAt: runAt.UTC()}
+	if payload != nil {
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return nil, err
+		}
+		s := string(data)
+		job.Payload = &s
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+// CancelJobs removes the pending jobs of a kind for a referenced row
+func CancelJobs(tx *gorm.DB, kind string, refIDs ...uuid.UUID) error {
+	if len(refIDs) == 0 {
+		return nil
+	}
+	return tx.Where("kind = ? AND ref_id IN ?", kind, refIDs).Delete(&models.ScheduledJob{}).Error
+}
+
+// StartScheduler polls for due jobs every interval in the background.
+// Several server instances can share a database: each job is claimed with a
+// conditional update, so only one instance runs it.
+func StartScheduler(interval time.Duration) {
+	go func() {
+		runDueJobs()
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runDueJobs()
+		}
+	}()
+}
+
+func runDueJobs() {
+	// Times are stored in UTC so they compare correctly as SQLite text
+	now := time.Now().UTC()
+
+	var jobs []models.ScheduledJob
+	database.DB.Where("run_at <= ? AND (locked_until IS NULL OR locked_until < ?)", now, now).
+		Order("run_at ASC").
+		Limit(jobBatchSize).
+		Find(&jobs)
+
+	for i := range jobs {
+		job := &jobs[i]
+		claim := database.DB.Model(&models.ScheduledJob{}).
+			Where("id = ? AND (locked_until IS NULL OR locked_until < ?)", job.ID, now).
+			Update("locked_until", now.Add(jobLease))
+		if claim.Error != nil || claim.RowsAffected == 0 {
+			continue // another instance got there first
+		}
+		runJob(job)
+	}
+}
+
+func runJob(job *models.ScheduledJob) {
+	jobHandlersMu.RLock()
+	handler, ok := jobHandlers[job.Kind]
+	jobHandlersMu.RUnlock()
+	if !ok {
+		log.Printf("Scheduler: no handler for job kind %q, dropping job %s", job.Kind, job.ID)
+		database.DB.Delete(job)
+		return
+	}
+
+	next, err := safeRun(handler, job)
+	if err != nil {
+		job.Attempts++
+		if job.Attempts >= jobMaxAttempts {
+			log.Printf("Scheduler: job %s (%s) failed %d times, giving up: %v", job.ID, job.Kind, job.Attempts, err)
+			database.DB.Delete(job)
+			return
+		}
+		msg := err.Error()
+		backoff := time.Duration(job.Attempts*job.Attempts) * time.Minute
+		database.DB.Model(job).Updates(map[string]interface{}{
+			"attempts":     job.Attempts,
+			"last_error":   msg,
+			"run_at":       time.Now().UTC().Add(backoff),
+			"locked_until": nil,
+		})
+		return
+	}
+
+	if next == nil {
+		database.DB.Delete(job)
+		return
+	}
+	database.DB.Model(job).Updates(map[string]interface{}{
+		"run_at":       next.UTC(),
+		"attempts":     0,
+		"last_error":   nil,
+		"locked_until": nil,
+	})
+}
+
+// safeRun turns a handler panic into an error so one bad job can't stop the loop
+func safeRun(handler JobHandler, job *models.ScheduledJob) (next *time.Time, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler(job)
+}